@@ -87,6 +87,19 @@ func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+type FloatLiteral struct {
+	Token token.Token // the token.FLOAT token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
 type StringLiteral struct {
 	Token token.Token // the token.STRING token
 	Value string
@@ -100,6 +113,33 @@ func (sl *StringLiteral) String() string {
 	return sl.Token.Literal
 }
 
+// A string with ${} interpolations. Parts alternates *StringLiteral, for the text, with the embedded expressions
+type TemplateLiteral struct {
+	Token token.Token // the token.TEMPLATE_HEAD token
+	Parts []Expression
+}
+
+func (tl *TemplateLiteral) expressionNode() {}
+func (tl *TemplateLiteral) TokenLiteral() string {
+	return tl.Token.Literal
+}
+func (tl *TemplateLiteral) String() string {
+	var out bytes.Buffer
+
+	for _, part := range tl.Parts {
+		if str, ok := part.(*StringLiteral); ok {
+			out.WriteString(str.Value)
+			continue
+		}
+
+		out.WriteString("${")
+		out.WriteString(part.String())
+		out.WriteString("}")
+	}
+
+	return out.String()
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /**********************         LET         ********************************/
@@ -107,9 +147,10 @@ func (sl *StringLiteral) String() string {
 /***************************************************************************/
 
 type LetStatement struct {
-	Token token.Token // the token.LET token
-	Name  *Identifier
-	Value Expression
+	Token   token.Token // the token.LET token
+	Name    *Identifier
+	Pattern Expression // an ArrayPattern or a HashPattern destructuring the value, in place of Name
+	Value   Expression
 }
 
 func (ls *LetStatement) statementNode() {}
@@ -120,7 +161,11 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -132,6 +177,138 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+/***************************************************************************/
+/***************************************************************************/
+/**********************        PATTERNS       ******************************/
+/***************************************************************************/
+/***************************************************************************/
+
+// Destructures an array: [a, [b, c], ...rest]
+// Elements are Identifiers or nested patterns, and there must be one for each element of the array unless there's a Rest
+type ArrayPattern struct {
+	Token    token.Token // the '[' token
+	Elements []Expression
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) expressionNode() {}
+func (ap *ArrayPattern) TokenLiteral() string {
+	return ap.Token.Literal
+}
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// Destructures a hash with string keys: {name, age: years, address: {city}}
+// Values are aligned with Keys: the key itself when it's bound to a variable with the same name
+type HashPattern struct {
+	Token  token.Token // the '{' token
+	Keys   []*Identifier
+	Values []Expression
+}
+
+func (hp *HashPattern) expressionNode() {}
+func (hp *HashPattern) TokenLiteral() string {
+	return hp.Token.Literal
+}
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for i, key := range hp.Keys {
+		if ident, ok := hp.Values[i].(*Identifier); ok && ident.Value == key.Value {
+			pairs = append(pairs, key.String())
+		} else {
+			pairs = append(pairs, key.String()+": "+hp.Values[i].String())
+		}
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/**********************         CONST         ******************************/
+/***************************************************************************/
+/***************************************************************************/
+
+// Like a let statement, but the binding can't be changed afterwards
+type ConstStatement struct {
+	Token token.Token // the token.CONST token
+	Name  *Identifier
+	Value Expression
+}
+
+func (cs *ConstStatement) statementNode() {}
+func (cs *ConstStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+func (cs *ConstStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(cs.TokenLiteral() + " ")
+	out.WriteString(cs.Name.String())
+	out.WriteString(" = ")
+
+	if cs.Value != nil {
+		out.WriteString(cs.Value.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/*********************         FUNCTION       ******************************/
+/***************************************************************************/
+/***************************************************************************/
+
+// A named function declaration: fn name(a, b) { ... }
+// Declarations are bound before the statements of their block run, so they can be called before they appear
+type FunctionStatement struct {
+	Token    token.Token // the token.FUNCTION token
+	Name     *Identifier
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode() {}
+func (fs *FunctionStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+func (fs *FunctionStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(fs.TokenLiteral() + " ")
+	out.WriteString(fs.Name.String())
+	out.WriteString("(")
+	out.WriteString(fs.Function.ParametersString())
+	out.WriteString(")")
+	out.WriteString(fs.Function.Body.String())
+
+	return out.String()
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /*********************         RETURN         ******************************/
@@ -161,6 +338,94 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+/***************************************************************************/
+/***************************************************************************/
+/**********************         WHILE         ******************************/
+/***************************************************************************/
+/***************************************************************************/
+
+type WhileStatement struct {
+	Token     token.Token // the token.WHILE token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+func (ws *WhileStatement) TokenLiteral() string {
+	return ws.Token.Literal
+}
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while (")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(") ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/***********************         FOR         *******************************/
+/***************************************************************************/
+/***************************************************************************/
+
+// for (Variable in Iterable) { Body }
+type ForStatement struct {
+	Token    token.Token // the token.FOR token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+func (fs *ForStatement) TokenLiteral() string {
+	return fs.Token.Literal
+}
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	out.WriteString(fs.Variable.String())
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/******************         BREAK AND CONTINUE         *********************/
+/***************************************************************************/
+/***************************************************************************/
+
+type BreakStatement struct {
+	Token token.Token // the token.BREAK token
+}
+
+func (bs *BreakStatement) statementNode() {}
+func (bs *BreakStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+func (bs *BreakStatement) String() string {
+	return bs.TokenLiteral() + ";"
+}
+
+type ContinueStatement struct {
+	Token token.Token // the token.CONTINUE token
+}
+
+func (cs *ContinueStatement) statementNode() {}
+func (cs *ContinueStatement) TokenLiteral() string {
+	return cs.Token.Literal
+}
+func (cs *ContinueStatement) String() string {
+	return cs.TokenLiteral() + ";"
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /********************         EXPRESSION         ***************************/
@@ -238,6 +503,35 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+/***************************************************************************/
+/***************************************************************************/
+/*****************        ASSIGN EXPRESSION         ************************/
+/***************************************************************************/
+/***************************************************************************/
+
+// Target is either an *Identifier or an *IndexExpression
+type AssignExpression struct {
+	Token  token.Token // The = token
+	Target Expression
+	Value  Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" = ")
+	out.WriteString(ae.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /***********************        BOOLEAN         ****************************/
@@ -272,19 +566,112 @@ func (ie *IfExpression) expressionNode() {}
 func (ie *IfExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
+// Written the way it's parsed, so that the output can be parsed again: if (condition) { ... } else { ... }
+// An alternative made of just another if expression is written as an else if
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("if")
+	out.WriteString("if (")
 	out.WriteString(ie.Condition.String())
-	out.WriteString(" ")
-	out.WriteString(ie.Consequence.String())
+	out.WriteString(") ")
+	out.WriteString(bracedBlock(ie.Consequence))
 
-	if ie.Alternative != nil {
-		out.WriteString("else ")
-		out.WriteString(ie.Alternative.String())
+	if ie.Alternative == nil {
+		return out.String()
 	}
 
+	out.WriteString(" else ")
+
+	if len(ie.Alternative.Statements) == 1 {
+		if stmt, ok := ie.Alternative.Statements[0].(*ExpressionStatement); ok {
+			if elseIf, ok := stmt.Expression.(*IfExpression); ok {
+				out.WriteString(elseIf.String())
+				return out.String()
+			}
+		}
+	}
+
+	out.WriteString(bracedBlock(ie.Alternative))
+
+	return out.String()
+}
+
+// The statements of a block in braces, separated by semicolons so that each one is parsed on its own: { a; b }
+func bracedBlock(block *BlockStatement) string {
+	var out bytes.Buffer
+
+	out.WriteString("{ ")
+
+	for i, s := range block.Statements {
+		if i > 0 {
+			if !strings.HasSuffix(out.String(), ";") {
+				out.WriteString(";")
+			}
+			out.WriteString(" ")
+		}
+		out.WriteString(s.String())
+	}
+
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/********************        MATCH EXPRESSION        ***********************/
+/***************************************************************************/
+/***************************************************************************/
+
+// match (subject) { pattern => result, pattern if guard => result }
+// The value of the expression is the result of the first arm whose pattern matches the subject and whose guard holds
+type MatchExpression struct {
+	Token   token.Token // The 'match' token
+	Subject Expression
+	Arms    []*MatchArm
+}
+
+// Patterns are literals, identifiers binding the value (with _ matching anything), or array and hash patterns
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression // nil when the arm has no guard
+	Result  Expression
+}
+
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+
+	if ma.Guard != nil {
+		out.WriteString(" if ")
+		out.WriteString(ma.Guard.String())
+	}
+
+	out.WriteString(" => ")
+	out.WriteString(ma.Result.String())
+
+	return out.String()
+}
+
+func (me *MatchExpression) expressionNode() {}
+func (me *MatchExpression) TokenLiteral() string {
+	return me.Token.Literal
+}
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("match")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
 	return out.String()
 }
 
@@ -318,8 +705,11 @@ func (bs *BlockStatement) String() string {
 /***************************************************************************/
 /***************************************************************************/
 type FunctionLiteral struct {
-	Token      token.Token // The fn token
-	Parameters []*Identifier
+	Token      token.Token   // The fn token
+	Parameters []*Identifier // The names of the parameters: nil for a destructured one, which can't be passed by keyword
+	Defaults   []Expression  // Default values, aligned with Parameters: nil when the parameter has none
+	Patterns   []Expression  // Destructuring patterns, aligned with Parameters: nil for plain parameters
+	Rest       *Identifier   // The ...rest parameter collecting the remaining arguments, if any
 	Body       *BlockStatement
 }
 
@@ -330,20 +720,40 @@ func (fl *FunctionLiteral) TokenLiteral() string {
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range fl.Parameters {
-		params = append(params, p.String())
-	}
-
 	out.WriteString(fl.TokenLiteral())
 	out.WriteString("(")
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(fl.ParametersString())
 	out.WriteString(")")
 	out.WriteString(fl.Body.String())
 
 	return out.String()
 }
 
+// The parameters as they are written in the source, without parentheses: a, b = 2, ...rest
+func (fl *FunctionLiteral) ParametersString() string {
+	params := []string{}
+	for i, p := range fl.Parameters {
+		var param string
+		if p != nil {
+			param = p.String()
+		} else if i < len(fl.Patterns) && fl.Patterns[i] != nil {
+			param = fl.Patterns[i].String()
+		}
+
+		if i < len(fl.Defaults) && fl.Defaults[i] != nil {
+			param += " = " + fl.Defaults[i].String()
+		}
+
+		params = append(params, param)
+	}
+
+	if fl.Rest != nil {
+		params = append(params, "..."+fl.Rest.String())
+	}
+
+	return strings.Join(params, ", ")
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /********************        CALL EXPRESSION         ***********************/
@@ -375,6 +785,47 @@ func (ce *CallExpression) String() string {
 	return out.String()
 }
 
+/***************************************************************************/
+/***************************************************************************/
+/**********************        SPREAD EXPRESSION        ********************/
+/***************************************************************************/
+/***************************************************************************/
+
+// ...value in a list of arguments or array elements, replaced by the elements of the array it evaluates to
+type SpreadExpression struct {
+	Token token.Token // The ... token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode() {}
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+
+/***************************************************************************/
+/***************************************************************************/
+/**********************        KEYWORD ARGUMENT         ********************/
+/***************************************************************************/
+/***************************************************************************/
+
+// name: value in the arguments of a call, binding the value to the parameter with that name
+type KeywordArgument struct {
+	Token token.Token // The name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (ka *KeywordArgument) expressionNode() {}
+func (ka *KeywordArgument) TokenLiteral() string {
+	return ka.Token.Literal
+}
+func (ka *KeywordArgument) String() string {
+	return ka.Name.String() + ": " + ka.Value.String()
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /*********************        ARRAY LITERAL         ************************/
@@ -426,6 +877,36 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+// array[start:end] or string[start:end], where both bounds can be omitted
+type SliceExpression struct {
+	Token token.Token // The '[' token
+	Left  Expression
+	Start Expression // nil when omitted, the slice starts from the beginning
+	End   Expression // nil when omitted, the slice goes on to the end
+}
+
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Start != nil {
+		out.WriteString(se.Start.String())
+	}
+	out.WriteString(":")
+	if se.End != nil {
+		out.WriteString(se.End.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
 /***************************************************************************/
 /***************************************************************************/
 /**********************        HASH LITERAL         ************************/
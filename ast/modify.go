@@ -20,10 +20,23 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		node.ReturnValue, _ = Modify(node.ReturnValue, modifier).(Expression)
 	case *LetStatement:
 		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *ConstStatement:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *FunctionStatement:
+		node.Function, _ = Modify(node.Function, modifier).(*FunctionLiteral)
+	case *WhileStatement:
+		node.Condition, _ = Modify(node.Condition, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *ForStatement:
+		node.Iterable, _ = Modify(node.Iterable, modifier).(Expression)
+		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
 
 	case *InfixExpression:
 		node.Left, _ = Modify(node.Left, modifier).(Expression)
 		node.Right, _ = Modify(node.Right, modifier).(Expression)
+	case *AssignExpression:
+		node.Target, _ = Modify(node.Target, modifier).(Expression)
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *PrefixExpression:
 		node.Right, _ = Modify(node.Right, modifier).(Expression)
 	case *IfExpression:
@@ -32,11 +45,30 @@ func Modify(node Node, modifier ModifierFunc) Node {
 		if node.Alternative != nil {
 			node.Alternative, _ = Modify(node.Alternative, modifier).(*BlockStatement)
 		}
+	case *MatchExpression:
+		node.Subject, _ = Modify(node.Subject, modifier).(Expression)
+		for _, arm := range node.Arms {
+			if arm.Guard != nil {
+				arm.Guard, _ = Modify(arm.Guard, modifier).(Expression)
+			}
+			arm.Result, _ = Modify(arm.Result, modifier).(Expression)
+		}
 	case *FunctionLiteral:
 		for i, param := range node.Parameters {
-			node.Parameters[i], _ = Modify(param, modifier).(*Identifier)
+			if param != nil {
+				node.Parameters[i], _ = Modify(param, modifier).(*Identifier)
+			}
+		}
+		for i, def := range node.Defaults {
+			if def != nil {
+				node.Defaults[i], _ = Modify(def, modifier).(Expression)
+			}
 		}
 		node.Body, _ = Modify(node.Body, modifier).(*BlockStatement)
+	case *SpreadExpression:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
+	case *KeywordArgument:
+		node.Value, _ = Modify(node.Value, modifier).(Expression)
 	case *ArrayLiteral:
 		for i, el := range node.Elements {
 			node.Elements[i], _ = Modify(el, modifier).(Expression)
@@ -50,9 +82,21 @@ func Modify(node Node, modifier ModifierFunc) Node {
 			newPairs[newKey] = newValue
 		}
 		node.Pairs = newPairs
+	case *TemplateLiteral:
+		for i, part := range node.Parts {
+			node.Parts[i], _ = Modify(part, modifier).(Expression)
+		}
 	case *IndexExpression:
 		node.Left, _ = Modify(node.Left, modifier).(Expression)
 		node.Index, _ = Modify(node.Index, modifier).(Expression)
+	case *SliceExpression:
+		node.Left, _ = Modify(node.Left, modifier).(Expression)
+		if node.Start != nil {
+			node.Start, _ = Modify(node.Start, modifier).(Expression)
+		}
+		if node.End != nil {
+			node.End, _ = Modify(node.End, modifier).(Expression)
+		}
 	}
 
 	// Base recursion case (no children) we return the modified node
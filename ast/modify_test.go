@@ -53,6 +53,10 @@ func TestModify(t *testing.T) {
 			&InfixExpression{Left: two(), Operator: "+", Right: one()},
 			&InfixExpression{Left: two(), Operator: "+", Right: two()},
 		},
+		{
+			&AssignExpression{Target: &IndexExpression{Left: one(), Index: one()}, Value: one()},
+			&AssignExpression{Target: &IndexExpression{Left: two(), Index: two()}, Value: two()},
+		},
 		{
 			&PrefixExpression{Operator: "-", Right: one()},
 			&PrefixExpression{Operator: "-", Right: two()},
@@ -61,6 +65,14 @@ func TestModify(t *testing.T) {
 			&IndexExpression{Left: one(), Index: one()},
 			&IndexExpression{Left: two(), Index: two()},
 		},
+		{
+			&SliceExpression{Left: one(), Start: one(), End: one()},
+			&SliceExpression{Left: two(), Start: two(), End: two()},
+		},
+		{
+			&SliceExpression{Left: one(), End: one()},
+			&SliceExpression{Left: two(), End: two()},
+		},
 		{
 			&IfExpression{
 				Condition: one(),
@@ -97,6 +109,70 @@ func TestModify(t *testing.T) {
 			&LetStatement{Value: one()},
 			&LetStatement{Value: two()},
 		},
+		{
+			&ConstStatement{Value: one()},
+			&ConstStatement{Value: two()},
+		},
+		{
+			&FunctionStatement{
+				Function: &FunctionLiteral{
+					Parameters: []*Identifier{},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{Expression: one()},
+						},
+					},
+				},
+			},
+			&FunctionStatement{
+				Function: &FunctionLiteral{
+					Parameters: []*Identifier{},
+					Body: &BlockStatement{
+						Statements: []Statement{
+							&ExpressionStatement{Expression: two()},
+						},
+					},
+				},
+			},
+		},
+		{
+			&WhileStatement{
+				Condition: one(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&WhileStatement{
+				Condition: two(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
+		{
+			&ForStatement{
+				Variable: &Identifier{Value: "x"},
+				Iterable: one(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: one()},
+					},
+				},
+			},
+			&ForStatement{
+				Variable: &Identifier{Value: "x"},
+				Iterable: two(),
+				Body: &BlockStatement{
+					Statements: []Statement{
+						&ExpressionStatement{Expression: two()},
+					},
+				},
+			},
+		},
 		{
 			&FunctionLiteral{
 				Parameters: []*Identifier{},
@@ -115,10 +191,46 @@ func TestModify(t *testing.T) {
 				},
 			},
 		},
+		{
+			&FunctionLiteral{
+				Parameters: []*Identifier{},
+				Defaults:   []Expression{one()},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+			&FunctionLiteral{
+				Parameters: []*Identifier{},
+				Defaults:   []Expression{two()},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+		},
+		{
+			&FunctionLiteral{
+				Parameters: []*Identifier{nil},
+				Defaults:   []Expression{one()},
+				Patterns:   []Expression{&ArrayPattern{Elements: []Expression{&Identifier{Value: "a"}}}},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+			&FunctionLiteral{
+				Parameters: []*Identifier{nil},
+				Defaults:   []Expression{two()},
+				Patterns:   []Expression{&ArrayPattern{Elements: []Expression{&Identifier{Value: "a"}}}},
+				Body:       &BlockStatement{Statements: []Statement{}},
+			},
+		},
+		{&SpreadExpression{Value: one()}, &SpreadExpression{Value: two()}},
+		{
+			&MatchExpression{Subject: one(), Arms: []*MatchArm{{Pattern: one(), Guard: one(), Result: one()}}},
+			&MatchExpression{Subject: two(), Arms: []*MatchArm{{Pattern: one(), Guard: two(), Result: two()}}},
+		},
+		{&KeywordArgument{Value: one()}, &KeywordArgument{Value: two()}},
 		{
 			&ArrayLiteral{Elements: []Expression{one(), one()}},
 			&ArrayLiteral{Elements: []Expression{two(), two()}},
 		},
+		{
+			&TemplateLiteral{Parts: []Expression{&StringLiteral{Value: "a"}, one()}},
+			&TemplateLiteral{Parts: []Expression{&StringLiteral{Value: "a"}, two()}},
+		},
 	}
 
 	for _, tt := range tests {
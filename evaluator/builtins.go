@@ -2,22 +2,46 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/akyrey/monkey-programming-language/object"
 )
 
 var builtins = map[string]*object.Builtin{
+	// Strings are measured in bytes by default, pass "chars" as second argument to count characters instead
 	"len": {
 		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return newError("wrong number of arguments. Got %d. Want 1", len(args))
+			if len(args) != 1 && len(args) != 2 {
+				return newError("wrong number of arguments. Got %d. Want 1 or 2", len(args))
+			}
+
+			if len(args) == 2 && args[0].Type() != object.STRING_OBJ {
+				return newError("second argument to `len` only supported for STRING. Got %s", args[0].Type())
 			}
 
 			switch arg := args[0].(type) {
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				if len(args) == 1 {
+					return &object.Integer{Value: int64(len(arg.Value))}
+				}
+
+				unit, ok := args[1].(*object.String)
+				if !ok {
+					return newError("second argument to `len` must be STRING. Got %s", args[1].Type())
+				}
+
+				switch unit.Value {
+				case "bytes":
+					return &object.Integer{Value: int64(len(arg.Value))}
+				case "chars":
+					return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+				default:
+					return newError("unknown unit for `len`: %q. Want \"bytes\" or \"chars\"", unit.Value)
+				}
 			default:
 				return newError("argument to `len` not supported. Got %s", args[0].Type())
 			}
@@ -117,4 +141,91 @@ var builtins = map[string]*object.Builtin{
 			return NULL
 		},
 	},
+	// Conversions between numbers (and from strings). Floats are truncated towards zero
+	"int": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. Got %d. Want 1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return newError("cannot convert %s to INTEGER", arg.Inspect())
+				}
+
+				return &object.Integer{Value: int64(arg.Value)}
+			case *object.String:
+				value, err := strconv.ParseInt(arg.Value, 10, 64)
+				if err != nil {
+					return newError("could not parse %q as integer", arg.Value)
+				}
+
+				return &object.Integer{Value: value}
+			default:
+				return newError("argument to `int` not supported. Got %s", args[0].Type())
+			}
+		},
+	},
+	"float": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. Got %d. Want 1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return &object.Float{Value: float64(arg.Value)}
+			case *object.Float:
+				return arg
+			case *object.String:
+				value, err := strconv.ParseFloat(arg.Value, 64)
+				if err != nil {
+					return newError("could not parse %q as float", arg.Value)
+				}
+
+				return &object.Float{Value: value}
+			default:
+				return newError("argument to `float` not supported. Got %s", args[0].Type())
+			}
+		},
+	},
+	// Makes arrays and hashes immutable, together with the arrays and hashes inside them
+	"freeze": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. Got %d. Want 1", len(args))
+			}
+
+			freeze(args[0])
+
+			return args[0]
+		},
+	},
+}
+
+// A frozen object only contains frozen objects, so we can stop there. This also takes care of cycles
+func freeze(obj object.Object) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		if obj.Frozen {
+			return
+		}
+
+		obj.Frozen = true
+		for _, el := range obj.Elements {
+			freeze(el)
+		}
+	case *object.Hash:
+		if obj.Frozen {
+			return
+		}
+
+		obj.Frozen = true
+		for _, pair := range obj.Pairs {
+			freeze(pair.Value)
+		}
+	}
 }
@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 
 	"github.com/akyrey/monkey-programming-language/ast"
@@ -9,9 +11,11 @@ import (
 )
 
 var (
-	NULL  = &object.Null{}
-	TRUE  = &object.Boolean{Value: true}
-	FALSE = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	TRUE     = &object.Boolean{Value: true}
+	FALSE    = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
@@ -36,13 +40,43 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+
+		if node.Pattern != nil {
+			return bindPattern(node.Pattern, val, env)
+		}
+
+		if result := env.Set(node.Name.Value, val); isError(result) {
+			return result
+		}
+	case *ast.ConstStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+
+		if result := env.SetConst(node.Name.Value, val); isError(result) {
+			return result
+		}
+	case *ast.FunctionStatement:
+		// Already bound by hoistFunctions when the block started, like a let it evaluates to NULL
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 
 		// Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.TemplateLiteral:
+		return evalTemplateLiteral(node, env)
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
@@ -58,20 +92,26 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return left
 		}
 
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, left, env)
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 
 		return evalInfixExpression(node.Operator, left, right)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
-		params := node.Parameters
-		body := node.Body
-		return &object.Function{Parameters: params, Body: body, Env: env}
+		return newFunction(node, env)
 	case *ast.CallExpression:
 		if node.Function.TokenLiteral() == "quote" {
 			return quote(node.Arguments[0], env)
@@ -82,12 +122,12 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return function
 		}
 
-		args := evalExpressions(node.Arguments, env)
+		args, keywords := evalArguments(node.Arguments, env)
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, keywords)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
@@ -97,6 +137,11 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Array{Elements: elements}
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.SpreadExpression:
+		// Lists of expressions expand it, anywhere else it has no meaning
+		return newError("spread operator outside of a list: %s", node.String())
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -115,6 +160,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 }
 
 func evalProgram(statements []ast.Statement, env *object.Environment) object.Object {
+	if err := hoistFunctions(statements, env); err != nil {
+		return err
+	}
+
 	var result object.Object
 
 	for _, statement := range statements {
@@ -125,14 +174,61 @@ func evalProgram(statements []ast.Statement, env *object.Environment) object.Obj
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("%s outside of a loop", result.Inspect())
 		}
 	}
 
 	return result
 }
 
+func newFunction(lit *ast.FunctionLiteral, env *object.Environment) *object.Function {
+	return &object.Function{
+		Parameters: lit.Parameters,
+		Defaults:   lit.Defaults,
+		Patterns:   lit.Patterns,
+		Rest:       lit.Rest,
+		Body:       lit.Body,
+		Env:        env,
+	}
+}
+
+// Binds the functions declared in a list of statements before any of them runs,
+// so that they can call each other whatever order they are written in.
+// A constant declared in the same list can't share a name with them, wherever it is
+func hoistFunctions(statements []ast.Statement, env *object.Environment) object.Object {
+	constants := map[string]bool{}
+
+	for _, statement := range statements {
+		if cs, ok := statement.(*ast.ConstStatement); ok {
+			constants[cs.Name.Value] = true
+		}
+	}
+
+	for _, statement := range statements {
+		fs, ok := statement.(*ast.FunctionStatement)
+		if !ok {
+			continue
+		}
+
+		if constants[fs.Name.Value] {
+			return newError("cannot redeclare constant: %s", fs.Name.Value)
+		}
+
+		if result := env.Set(fs.Name.Value, newFunction(fs.Function, env)); isError(result) {
+			return result
+		}
+	}
+
+	return nil
+}
+
 // Separate from evalProgram since block statements can be nested and we only want to return in the outermost
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	if err := hoistFunctions(block.Statements, env); err != nil {
+		return err
+	}
+
 	var result object.Object
 
 	for _, statement := range block.Statements {
@@ -140,7 +236,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -149,6 +245,79 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 	return result
 }
 
+// Like if blocks, the body of a loop doesn't get its own environment
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, stop := evalLoopBody(ws.Body, env); stop {
+			return result
+		}
+	}
+}
+
+// Arrays give us their elements, strings their chars and hashes their keys
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var values []object.Object
+
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		values = iterable.Elements
+	case *object.String:
+		for _, ch := range iterable.Value {
+			values = append(values, &object.String{Value: string(ch)})
+		}
+	case *object.Hash:
+		values = sortedHashKeys(iterable)
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	for _, value := range values {
+		if result := env.Set(fs.Variable.Value, value); isError(result) {
+			return result
+		}
+
+		if result, stop := evalLoopBody(fs.Body, env); stop {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+// Runs an iteration, telling whether the loop has to stop and what it evaluates to in that case.
+// The constants declared by the body last for the iteration only, so the next one can declare them again
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	defer env.DropConstants(env.ConstantCount())
+
+	result := Eval(body, env)
+	if result == nil {
+		return nil, false
+	}
+
+	switch result.Type() {
+	case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+		return result, true
+	case object.BREAK_OBJ:
+		return NULL, true
+	}
+
+	return nil, false
+}
+
 // Since the boolean values are only true and false, it's useless to create a new object everytime
 // we need to represent a value
 func nativeBoolToBooleanObject(input bool) *object.Boolean {
@@ -165,6 +334,8 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalTildePrefixOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -177,6 +348,9 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	// Mixing integers and floats promotes the integer to a float
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -190,6 +364,25 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+// && and || short-circuit: the right operand is evaluated only when the left one doesn't decide the result
+// Operands can be of any type, their truthiness is what matters, and the result is always a boolean
+func evalLogicalExpression(node *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	if node.Operator == "&&" && !isTruthy(left) {
+		return FALSE
+	}
+
+	if node.Operator == "||" && isTruthy(left) {
+		return TRUE
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
 // This transforms true to false, false to true, null to true and any other value to false
 func evalBangOperatorExpression(right object.Object) object.Object {
 	switch right {
@@ -205,12 +398,24 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: -right.Value}
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
+}
+
+// Bitwise complement, only defined for integers
+func evalTildePrefixOperatorExpression(right object.Object) object.Object {
+	if right.Type() != object.INTEGER_OBJ {
+		return newError("unknown operator: ~%s", right.Type())
+	}
 
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return &object.Integer{Value: ^value}
 }
 
 func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
@@ -225,12 +430,74 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	case "*":
 		return &object.Integer{Value: leftVal * rightVal}
 	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
 		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<<", ">>":
+		if rightVal < 0 {
+			return newError("negative shift amount: %d", rightVal)
+		}
+
+		if operator == "<<" {
+			return &object.Integer{Value: leftVal << rightVal}
+		}
+		return &object.Integer{Value: leftVal >> rightVal}
+
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
 
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 	case "!=":
@@ -241,6 +508,19 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// Callers must make sure obj is a number with isNumber
+func toFloat(obj object.Object) float64 {
+	if i, ok := obj.(*object.Integer); ok {
+		return float64(i.Value)
+	}
+
+	return obj.(*object.Float).Value
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	leftVal := left.(*object.String).Value
 	rightVal := right.(*object.String).Value
@@ -257,6 +537,22 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 	}
 }
 
+// Every part of the template is converted to a string with Inspect, so any value can be interpolated
+func evalTemplateLiteral(node *ast.TemplateLiteral, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		evaluated := Eval(part, env)
+		if isError(evaluated) {
+			return evaluated
+		}
+
+		out.WriteString(evaluated.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if isError(condition) {
@@ -299,6 +595,81 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// An assignment evaluates to the assigned value, so that a = b = c works
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+
+		result, ok := env.Assign(target.Value, val)
+		if !ok {
+			return newError("assignment to undeclared variable: %s", target.Value)
+		}
+
+		return result
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+
+		return evalIndexAssignment(left, index, val)
+	default:
+		return newError("cannot assign to %s", node.Target)
+	}
+}
+
+// Arrays and hashes are changed in place, so every variable referring to them sees the new value
+func evalIndexAssignment(left, index, val object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		if left.Frozen {
+			return newError("cannot modify frozen ARRAY")
+		}
+
+		idx, ok := index.(*object.Integer)
+		if !ok {
+			return newError("index must be INTEGER, got %s", index.Type())
+		}
+
+		// Like reads, negative indexes count from the end
+		i := fromEnd(idx.Value, len(left.Elements))
+		if i < 0 || i >= int64(len(left.Elements)) {
+			return newError("index out of range: %d (length %d)", idx.Value, len(left.Elements))
+		}
+
+		left.Elements[i] = val
+	case *object.Hash:
+		if left.Frozen {
+			return newError("cannot modify frozen HASH")
+		}
+
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("type unusable as hash key: %s", index.Type())
+		}
+
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	return val
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -311,10 +682,26 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
+// A spread expression adds all the elements of its array to the result
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var result []object.Object
 
 	for _, e := range exps {
+		if spread, ok := e.(*ast.SpreadExpression); ok {
+			evaluated := Eval(spread.Value, env)
+			if isError(evaluated) {
+				return []object.Object{evaluated}
+			}
+
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				return []object.Object{newError("spread operator not supported: %s", evaluated.Type())}
+			}
+
+			result = append(result, array.Elements...)
+			continue
+		}
+
 		evaluated := Eval(e, env)
 		if isError(evaluated) {
 			return []object.Object{evaluated}
@@ -326,17 +713,66 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
+// Positional arguments are evaluated like the elements of an array, while keyword arguments are collected by name.
+// Errors are returned like evalExpressions does
+func evalArguments(exps []ast.Expression, env *object.Environment) ([]object.Object, map[string]object.Object) {
+	args := []object.Object{}
+	var keywords map[string]object.Object
+
+	for _, e := range exps {
+		kw, ok := e.(*ast.KeywordArgument)
+		if !ok {
+			evaluated := evalExpressions([]ast.Expression{e}, env)
+			if len(evaluated) == 1 && isError(evaluated[0]) {
+				return evaluated, nil
+			}
+
+			args = append(args, evaluated...)
+			continue
+		}
+
+		if _, ok := keywords[kw.Name.Value]; ok {
+			return []object.Object{newError("multiple values for parameter: %s", kw.Name.Value)}, nil
+		}
+
+		evaluated := Eval(kw.Value, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}, nil
+		}
+
+		if keywords == nil {
+			keywords = map[string]object.Object{}
+		}
+		keywords[kw.Name.Value] = evaluated
+	}
+
+	return args, keywords
+}
+
 // Performs the function body in a custom (function related) environment after checking the object
 //
 //	is indeed a function
-func applyFunction(fn object.Object, args []object.Object) object.Object {
+func applyFunction(fn object.Object, args []object.Object, keywords map[string]object.Object) object.Object {
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendedFunctionEnv(fn, args)
+		extendedEnv, err := extendedFunctionEnv(fn, args, keywords)
+		if err != nil {
+			return err
+		}
+
 		evaluated := Eval(fn.Body, extendedEnv)
 
+		// A loop can't be stopped from inside a function called in its body
+		if evaluated == BREAK || evaluated == CONTINUE {
+			return newError("%s outside of a loop", evaluated.Inspect())
+		}
+
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
+		if len(keywords) > 0 {
+			return newError("keyword arguments not supported by builtin functions")
+		}
+
 		return fn.Fn(args...)
 	default:
 		return newError("not a function: %s", fn.Type())
@@ -345,15 +781,227 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 
 // Add function arguments to extended environment
 // This avoid overwriting outer scopes variables
-// We are extending the function environment and not the global environment to also manage closures
-func extendedFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// We are extending the function environment and not the global environment to also manage closures.
+// Positional arguments are bound first, then keyword ones by name. A parameter left without a value takes its default,
+// evaluated in the new environment so that it can refer to the parameters before it.
+// The arguments left over are collected by the rest parameter
+func extendedFunctionEnv(fn *object.Function, args []object.Object, keywords map[string]object.Object) (*object.Environment, *object.Error) {
 	env := object.NewEnclosedEnvironment(fn.Env)
 
+	if len(args) > len(fn.Parameters) && fn.Rest == nil {
+		want := fmt.Sprintf("%d", len(fn.Parameters))
+		for _, def := range fn.Defaults {
+			if def != nil {
+				want = "at most " + want
+				break
+			}
+		}
+
+		return nil, newError("wrong number of arguments. Got %d. Want %s", len(args), want)
+	}
+
+	// Sorted, so that the same call always reports the same error
+	names := []string{}
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		i := 0
+		for i < len(fn.Parameters) && (fn.Parameters[i] == nil || fn.Parameters[i].Value != name) {
+			i += 1
+		}
+
+		if i == len(fn.Parameters) {
+			return nil, newError("unknown parameter: %s", name)
+		}
+		if i < len(args) {
+			return nil, newError("multiple values for parameter: %s", name)
+		}
+	}
+
 	for i, param := range fn.Parameters {
-		env.Set(param.Value, args[i])
+		var val object.Object
+
+		switch {
+		case i < len(args):
+			val = args[i]
+		case param != nil && keywords[param.Value] != nil:
+			val = keywords[param.Value]
+		case i < len(fn.Defaults) && fn.Defaults[i] != nil:
+			val = Eval(fn.Defaults[i], env)
+			if err, ok := val.(*object.Error); ok {
+				return nil, err
+			}
+		case param == nil:
+			return nil, newError("missing argument for destructured parameter: %s", fn.Patterns[i])
+		default:
+			return nil, newError("missing argument for parameter: %s", param.Value)
+		}
+
+		// Destructured parameters have no name, only their pattern
+		if param == nil {
+			if err, ok := bindPattern(fn.Patterns[i], val, env).(*object.Error); ok {
+				return nil, err
+			}
+			continue
+		}
+
+		env.Set(param.Value, val)
 	}
 
-	return env
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+
+		env.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+
+	return env, nil
+}
+
+// Binds the names in a pattern to the matching parts of val, checking that val has the shape the pattern expects.
+// Returns an error or nil
+func bindPattern(pattern ast.Expression, val object.Object, env *object.Environment) object.Object {
+	mismatch, err := matchPattern(pattern, val, env)
+	if err != nil {
+		return err
+	}
+
+	if mismatch != "" {
+		return newError("%s", mismatch)
+	}
+
+	return nil
+}
+
+// Matches val against a pattern, binding the names in it. When val doesn't have the shape of the pattern
+// we get a description of the difference, while errors come from binding the names
+func matchPattern(pattern ast.Expression, val object.Object, env *object.Environment) (string, object.Object) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		// The wildcard matches anything without binding it
+		if pattern.Value == "_" {
+			return "", nil
+		}
+
+		if result := env.Set(pattern.Value, val); isError(result) {
+			return "", result
+		}
+	case *ast.ArrayPattern:
+		array, ok := val.(*object.Array)
+		if !ok {
+			return fmt.Sprintf("cannot destructure %s with an array pattern", val.Type()), nil
+		}
+
+		got, want := len(array.Elements), len(pattern.Elements)
+		if got < want || (got > want && pattern.Rest == nil) {
+			if pattern.Rest != nil {
+				return fmt.Sprintf("wrong number of elements to destructure. Got %d. Want at least %d", got, want), nil
+			}
+			return fmt.Sprintf("wrong number of elements to destructure. Got %d. Want %d", got, want), nil
+		}
+
+		for i, element := range pattern.Elements {
+			if mismatch, err := matchPattern(element, array.Elements[i], env); mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := append([]object.Object{}, array.Elements[want:]...)
+			if result := env.Set(pattern.Rest.Value, &object.Array{Elements: rest}); isError(result) {
+				return "", result
+			}
+		}
+	case *ast.HashPattern:
+		hash, ok := val.(*object.Hash)
+		if !ok {
+			return fmt.Sprintf("cannot destructure %s with a hash pattern", val.Type()), nil
+		}
+
+		for i, key := range pattern.Keys {
+			pair, ok := hash.Pairs[(&object.String{Value: key.Value}).HashKey()]
+			if !ok {
+				return fmt.Sprintf("missing key to destructure: %s", key.Value), nil
+			}
+
+			if mismatch, err := matchPattern(pattern.Values[i], pair.Value, env); mismatch != "" || err != nil {
+				return mismatch, err
+			}
+		}
+	default:
+		// A literal, the value must be equal to it
+		expected := Eval(pattern, env)
+		if isError(expected) {
+			return "", expected
+		}
+
+		if !literalsEqual(expected, val) {
+			return fmt.Sprintf("%s doesn't match %s", val.Inspect(), pattern.String()), nil
+		}
+	}
+
+	return "", nil
+}
+
+// Values of different types are never equal, not even 1 and 1.0
+func literalsEqual(a, b object.Object) bool {
+	switch a := a.(type) {
+	case *object.Integer:
+		b, ok := b.(*object.Integer)
+		return ok && a.Value == b.Value
+	case *object.Float:
+		b, ok := b.(*object.Float)
+		return ok && a.Value == b.Value
+	case *object.String:
+		b, ok := b.(*object.String)
+		return ok && a.Value == b.Value
+	default:
+		return a == b
+	}
+}
+
+// Tries the arms in order, each in its own environment so that the names bound by a pattern
+// are only visible to its guard and result
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(me.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	for _, arm := range me.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+
+		mismatch, err := matchPattern(arm.Pattern, subject, armEnv)
+		if err != nil {
+			return err
+		}
+		if mismatch != "" {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isError(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		return Eval(arm.Result, armEnv)
+	}
+
+	if env.NullOnFailedMatch() {
+		return NULL
+	}
+
+	return newError("no match for value: %s", subject.Inspect())
 }
 
 // This is required to stop the evaluation from the return of the last called function's body
@@ -369,6 +1017,8 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 	default:
@@ -376,10 +1026,11 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	}
 }
 
-// We check that the index is valid, otherwise we return NULL
+// We check that the index is valid, otherwise we return NULL.
+// Negative indexes count from the end, like slice bounds
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObj := array.(*object.Array)
-	idx := index.(*object.Integer).Value
+	idx := fromEnd(index.(*object.Integer).Value, len(arrayObj.Elements))
 	max := int64(len(arrayObj.Elements) - 1)
 
 	if idx < 0 || idx > max {
@@ -389,6 +1040,101 @@ func evalArrayIndexExpression(array, index object.Object) object.Object {
 	return arrayObj.Elements[idx]
 }
 
+// Strings are indexed by character, not by byte, and like arrays we return NULL for invalid indexes
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := fromEnd(index.(*object.Integer).Value, len(runes))
+
+	if idx < 0 || idx >= int64(len(runes)) {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+// A slice takes the elements from start up to end, excluded, and negative bounds count from the end.
+// Like indexes, bounds outside of the array or string give NULL, while a start after the end gives an empty slice.
+// Slices of arrays are copies, so that assigning to their elements doesn't change the original
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(se.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	var length int
+	var runes []rune
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		runes = []rune(left.Value)
+		length = len(runes)
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+
+	start, ok, err := evalSliceBound(se.Start, 0, length, env)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NULL
+	}
+
+	end, ok, err := evalSliceBound(se.End, length, length, env)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NULL
+	}
+
+	if start > end {
+		start = end
+	}
+
+	if array, ok := left.(*object.Array); ok {
+		return &object.Array{Elements: append([]object.Object{}, array.Elements[start:end]...)}
+	}
+
+	return &object.String{Value: string(runes[start:end])}
+}
+
+// Evaluates a bound of a slice, def when it's omitted, moving negative ones from the end.
+// Reports false when the bound is outside of the sliced value
+func evalSliceBound(exp ast.Expression, def, length int, env *object.Environment) (int, bool, object.Object) {
+	if exp == nil {
+		return def, true, nil
+	}
+
+	bound := Eval(exp, env)
+	if isError(bound) {
+		return 0, false, bound
+	}
+
+	integer, ok := bound.(*object.Integer)
+	if !ok {
+		return 0, false, newError("slice bound must be INTEGER, got %s", bound.Type())
+	}
+
+	idx := fromEnd(integer.Value, length)
+	if idx < 0 || idx > int64(length) {
+		return 0, false, nil
+	}
+
+	return int(idx), true, nil
+}
+
+// A negative index counts from the end of a list of the given length, so -1 is its last element
+func fromEnd(idx int64, length int) int64 {
+	if idx < 0 {
+		return idx + int64(length)
+	}
+
+	return idx
+}
+
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObj := hash.(*object.Hash)
 
@@ -405,6 +1151,45 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
+// Hashes don't keep the order of their pairs, so we sort the keys to always iterate them in the same order:
+// numbers first, then booleans and strings
+func sortedHashKeys(hash *object.Hash) []object.Object {
+	keys := []object.Object{}
+	for _, pair := range hash.Pairs {
+		keys = append(keys, pair.Key)
+	}
+
+	rank := func(obj object.Object) int {
+		switch obj.Type() {
+		case object.INTEGER_OBJ, object.FLOAT_OBJ:
+			return 0
+		case object.BOOLEAN_OBJ:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+
+		switch {
+		case isNumber(a):
+			return toFloat(a) < toFloat(b)
+		case a.Type() == object.BOOLEAN_OBJ:
+			return a == FALSE && b == TRUE
+		default:
+			return a.Inspect() < b.Inspect()
+		}
+	})
+
+	return keys
+}
+
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
 	pairs := make(map[object.HashKey]object.HashPair)
 
@@ -28,6 +28,21 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010 + 1_000", 1010},
+		{"0b1100 & 0b1010", 8},
+		{"0b1100 | 0b1010", 14},
+		{"0b1100 ^ 0b1010", 6},
+		{"~0", -1},
+		{"~5 & 0xFF", 250},
+		{"1 << 10", 1024},
+		{"1024 >> 3", 128},
+		{"-16 >> 2", -4},
+		{"1 | 2 << 2", 9},
+		{"10 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 10 % 4 * 2", 6},
 	}
 
 	for _, tt := range tests {
@@ -36,6 +51,48 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.5", 3.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"10 / 4.0", 2.5},
+		{"2.5 * 2", 5},
+		{"1e3 - 1", 999},
+		{"(1.5 + 2) * -2", -7},
+		{"7.5 % 2", 1.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"1.0 == 1", true},
+		{"1 != 1.0", false},
+		{"0.1 + 0.2 == 0.3", false},
+		{"2.5 > 2.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
 func TestStringExpressions(t *testing.T) {
 	input := `"Hello World!"`
 
@@ -66,6 +123,49 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestRawStrings(t *testing.T) {
+	input := "let pattern = `\\d+`;\nlet doc = \"\"\"\n  line one\n    line two\n  \"\"\";\npattern + \"|\" + doc"
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+
+	if !ok {
+		t.Fatalf("object is not String. Got %T (%+v)", evaluated, evaluated)
+	}
+
+	expected := "\\d+|line one\n  line two"
+	if str.Value != expected {
+		t.Errorf("String has wrong value. Want %q. Got %q", expected, str.Value)
+	}
+}
+
+func TestTemplateStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "Monkey"; "Hello ${name}!"`, "Hello Monkey!"},
+		{`let items = [1, 2, 3]; "you have ${len(items)} items"`, "you have 3 items"},
+		{`"${1 + 2} ${true} ${[1, 2]} ${1.5}"`, "3 true [1, 2] 1.5"},
+		{`let x = 5; "${"x is ${x}"}"`, "x is 5"},
+		{`"no interpolation: \${x}"`, "no interpolation: ${x}"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+
+		if !ok {
+			t.Errorf("object is not String. Got %T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. Want %q. Got %q", tt.expected, str.Value)
+		}
+	}
+}
+
 func TestStringComparison(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -108,6 +208,56 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"1.5 >= 1", true},
+		{"1 <= 0.5", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true || false", true},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
+		{`1 && "monkey"`, true},
+		{"0 || false", true},
+		{"true && !true || true", true},
+		{"let x = 5; x > 1 && x < 10", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLogicalOperatorsShortCircuit(t *testing.T) {
+	// The right operands would be errors if they were evaluated
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"false && foobar", false},
+		{"true || foobar", true},
+		{"false && (1 + true)", false},
+		{"true || 1 / 0", true},
 	}
 
 	for _, tt := range tests {
@@ -147,6 +297,10 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 } else { 30 }", 30},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 }", nil},
+		{"let x = 3; if (x == 1) { 10 } else if (x == 2) { 20 } else if (x == 3) { 30 } else { 40 }", 30},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +315,48 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 5) { let i = i + 1; }; i", 5},
+		{"let i = 0; while (i < 100000) { let i = i + 1; }; i", 100000},
+		{"let sum = 0; for (x in [1, 2, 3, 4]) { let sum = sum + x; }; sum", 10},
+		{"let i = 0; while (true) { let i = i + 1; if (i == 3) { break; } }; i", 3},
+		{"let s = 0; for (x in [1, 2, 3, 4, 5]) { if (x % 2 == 0) { continue; } let s = s + x; }; s", 9},
+		{"let n = 0; for (a in [1, 2, 3]) { for (b in [1, 2, 3]) { if (b == 2) { break; } let n = n + 1; } }; n", 3},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10; } } }; f()", 20},
+		{"let f = fn() { while (true) { return 7; } }; f()", 7},
+		{"let n = 0; for (x in []) { let n = n + 1; }; n", 0},
+		{"while (false) { 1 }", nil},
+		{"for (x in [1, 2]) { x }", nil},
+		{`let out = ""; for (c in "héllo") { let out = c + out; }; out`, "olléh"},
+		{`let out = ""; for (k in {"b": 1, "a": 2, true: 3, 10: 4, 2.5: 5}) { let out = out + "${k},"; }; out`, "2.5,10,true,a,b,"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. Got %T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if str.Value != expected {
+				t.Errorf("String has wrong value. Want %q. Got %q", expected, str.Value)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -189,6 +385,14 @@ func TestErrorHandling(t *testing.T) {
 		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
 		{"5 + true; 5;", "type mismatch: INTEGER + BOOLEAN"},
 		{"-true", "unknown operator: -BOOLEAN"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"1 << -1", "negative shift amount: -1"},
+		{"1 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"true && foobar", "identifier not found: foobar"},
+		{"false || 1 + true", "type mismatch: INTEGER + BOOLEAN"},
+		{`"value: ${foobar}"`, "identifier not found: foobar"},
 		{"true + false;", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"5; true + false; 5", "unknown operator: BOOLEAN + BOOLEAN"},
 		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
@@ -197,6 +401,60 @@ func TestErrorHandling(t *testing.T) {
 		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
 		{`999[1]`, "index operator not supported: INTEGER"},
 		{`{"name": "Monkey"}[fn(x) { x }];`, "type unusable as hash key: FUNCTION"},
+		{"break;", "break outside of a loop"},
+		{"if (true) { continue; }", "continue outside of a loop"},
+		{"let f = fn() { break; }; for (x in [1, 2]) { f(); }", "break outside of a loop"},
+		{"for (x in 5) { x }", "cannot iterate over INTEGER"},
+		{"while (1 + true) { 1 }", "type mismatch: INTEGER + BOOLEAN"},
+		{"y = 5", "assignment to undeclared variable: y"},
+		{"len = 1", "assignment to undeclared variable: len"},
+		{"let x = 1; x = y", "identifier not found: y"},
+		{"let a = [1]; a[1] = 2", "index out of range: 1 (length 1)"},
+		{"let a = [1]; a[-2] = 2", "index out of range: -2 (length 1)"},
+		{`let a = [1]; a["x"] = 2`, "index must be INTEGER, got STRING"},
+		{"let h = {}; h[fn(x) { x }] = 1", "type unusable as hash key: FUNCTION"},
+		{`let s = "abc"; s[0] = "x"`, "index assignment not supported: STRING"},
+		{"5[1:2]", "slice operator not supported: INTEGER"},
+		{`[1, 2][:"a"]`, "slice bound must be INTEGER, got STRING"},
+		{"[1, 2][x:]", "identifier not found: x"},
+		{"const x = 1; x = 2", "cannot assign to constant: x"},
+		{"const x = 1; let f = fn() { x = 2 }; f()", "cannot assign to constant: x"},
+		{"const x = 1; let x = 2", "cannot redeclare constant: x"},
+		{"const x = 1; const x = 2", "cannot redeclare constant: x"},
+		{"const x = 1; for (x in [1]) { x }", "cannot redeclare constant: x"},
+		{"for (x in [1]) { const y = x; }; y", "identifier not found: y"},
+		{"const c = 1; fn c() { 2 } c", "cannot redeclare constant: c"},
+		{"let f = fn() { fn g() { 1 } }; let y = f(); y + 1", "type mismatch: NULL + INTEGER"},
+		{"fn c() { 2 } const c = 1; c", "cannot redeclare constant: c"},
+		{"let f = fn() { fn c() { 2 } const c = 1; c }; f()", "cannot redeclare constant: c"},
+		{"let a = freeze([1, 2]); a[0] = 3", "cannot modify frozen ARRAY"},
+		{`let h = freeze({"a": 1}); h["b"] = 2`, "cannot modify frozen HASH"},
+		{`let h = freeze({"a": [1, {"b": 2}]}); h["a"][1]["b"] = 3`, "cannot modify frozen HASH"},
+		{"let a = [[1]]; freeze(a); a[0][0] = 2", "cannot modify frozen ARRAY"},
+		{"for (x in [1, 2]) { x + true }", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn(a, b) { a }; f(1)", "missing argument for parameter: b"},
+		{"let f = fn(a) { a }; f(1, 2)", "wrong number of arguments. Got 2. Want 1"},
+		{"let f = fn(a, b = 2) { a }; f(1, 2, 3)", "wrong number of arguments. Got 3. Want at most 2"},
+		{"let f = fn(a) { a }; f(b: 1)", "unknown parameter: b"},
+		{"let f = fn(a) { a }; f(1, a: 2)", "multiple values for parameter: a"},
+		{"let f = fn(a) { a }; f(a: 1, a: 2)", "multiple values for parameter: a"},
+		{"let f = fn(a = b) { a }; f()", "identifier not found: b"},
+		{"let f = fn(a) { a }; f(...5)", "spread operator not supported: INTEGER"},
+		{"len(x: [1])", "keyword arguments not supported by builtin functions"},
+		{"...[1]", "spread operator outside of a list: ...[1]"},
+		{"let [a, b] = 1", "cannot destructure INTEGER with an array pattern"},
+		{"let {a} = [1]", "cannot destructure ARRAY with a hash pattern"},
+		{"let [a, b] = [1]", "wrong number of elements to destructure. Got 1. Want 2"},
+		{"let [a] = [1, 2]", "wrong number of elements to destructure. Got 2. Want 1"},
+		{"let [a, b, ...c] = [1]", "wrong number of elements to destructure. Got 1. Want at least 2"},
+		{`let {a, b} = {"a": 1}`, "missing key to destructure: b"},
+		{"const a = 1; let [a] = [2]", "cannot redeclare constant: a"},
+		{"let f = fn([a]) { a }; f([])", "wrong number of elements to destructure. Got 0. Want 1"},
+		{"let f = fn(x, [a, b]) { a }; f(1)", "missing argument for destructured parameter: [a, b]"},
+		{"let f = fn([a]) { a }; f(a: [1])", "unknown parameter: a"},
+		{"match (3) { 1 => 1, 2 => 2 }", "no match for value: 3"},
+		{"match ([1]) { [a] if a + true => a }", "type mismatch: INTEGER + BOOLEAN"},
+		{"match (y) { _ => 1 }", "identifier not found: y"},
 	}
 
 	for _, tt := range tests {
@@ -215,6 +473,107 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"const a = 5; a;", 5},
+		{"const a = 5; let b = a * 2; b", 10},
+		{"const a = 5; let f = fn() { let a = 10; a = a + 1; a }; f() + a", 16},
+		{"const a = 5; let f = fn(a) { a }; f(7)", 7},
+		{"let a = 1; const a = 2; a", 2},
+		{"const a = [1, 2]; a[0] = 10; a[0]", 10},
+		{"let s = 0; for (x in [1, 2, 3]) { const y = x * 2; s = s + y; }; s", 12},
+		{"let i = 0; let s = 0; while (i < 3) { if (true) { const y = i; s = s + y; } i = i + 1; }; s", 3},
+		{"let y = 1; for (x in [5, 6]) { const y = x; }; y", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"fn double(x) { x * 2 }; double(5)", 10},
+		{"let a = double(3); fn double(x) { x * 2 }; a", 6},
+		{
+			`
+fn isEven(n) { if (n == 0) { true } else { isOdd(n - 1) } }
+fn isOdd(n) { if (n == 0) { false } else { isEven(n - 1) } }
+if (isEven(10)) { 1 } else { 0 }`,
+			1,
+		},
+		{"fn outer() { return inner() + 1; fn inner() { 41 } }; outer()", 42},
+		{"let x = 10; fn get() { x }; let x = 20; get()", 20},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// A declaration is a statement with no value, even as the last one of a block
+	for _, input := range []string{
+		"let f = fn() { fn g() { 1 } }; f()",
+		"if (true) { fn f() { 1 } }",
+		"fn f() { 1 }",
+	} {
+		testNullObject(t, testEval(input))
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let a = [1, 2]; let b = freeze(a); a == b", true},
+		{"let a = freeze([1, 2]); let b = push(a, 3); b[0] = 10; b[0] + a[0]", 11},
+		{"let a = [1]; a[0] = a; freeze(a); len(a)", 1},
+		{"freeze(5)", 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		}
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x = 2; x", 2},
+		{"let x = 1; x = 2", 2},
+		{"let x = 1; let y = x = 5; x + y", 10},
+		{"let x = 1; let f = fn() { x = x + 10 }; f(); f(); x", 21},
+		{"let x = 1; let f = fn() { let x = 2; x = 3; x }; f() + x", 4},
+		{"let i = 0; let s = 0; while (i < 5) { s = s + i; i = i + 1; }; s", 10},
+		{"let a = [1, 2, 3]; a[1] = 20; a[0] + a[1] + a[2]", 24},
+		{"let a = [1, 2]; let b = a; b[0] = 10; a[0]", 10},
+		{"let a = [1, 2, 3]; a[-1] = 9; a[2]", 9},
+		{"let a = [1, 2, 3]; a[-3] = 7; a[-3] + a[1]", 9},
+		{`let h = {"a": 1}; h["b"] = 2; h["a"] = 3; h["a"] + h["b"]`, 5},
+		{"let a = [[1], [2]]; a[1][0] = 5; a[1][0]", 5},
+		{"let a = [0, 0]; let i = 0; for (x in [3, 4]) { a[i] = x * 2; i = i + 1; }; a[0] + a[1]", 14},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestLetStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -232,6 +591,109 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let [a, b] = [1, 2]; a * 10 + b", 12},
+		{"let [a, ...rest] = [1, 2, 3]; len(rest) * 10 + a", 21},
+		{"let [a, ...rest] = [1]; len(rest)", 0},
+		{`let {name, age} = {"name": "x", "age": 3}; age`, 3},
+		{`let {age: years} = {"age": 3, "other": 4}; years`, 3},
+		{`let [a, {b, c: [d, e]}] = [1, {"b": 2, "c": [3, 4]}]; a + b + d + e`, 10},
+		{"let [a, [b, c]] = [1, [2, 3]]; a + b + c", 6},
+		{"let f = fn([a, b]) { a - b }; f([5, 2])", 3},
+		{`let f = fn({x, y} = {"x": 1, "y": 2}) { x + y }; f()`, 3},
+		{`fn area({width, height}) { width * height }; area({"height": 2, "width": 3})`, 6},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"match (1) { 1 => 10, 2 => 20 }", 10},
+		{"match (2) { 1 => 10, 2 => 20 }", 20},
+		{"match (-1) { -1 => 10, _ => 20 }", 10},
+		{"match (1.0) { 1 => 10, 1.0 => 20 }", 20},
+		{`match ("b") { "a" => 1, "b" => 2 }`, 2},
+		{"match (false) { true => 1, false => 2 }", 2},
+		{"match (5) { x => x * 2 }", 10},
+		{"match ([1, 2]) { [a] => a, [a, b] => a + b }", 3},
+		{"match ([1, 2, 3]) { [1, ...rest] => len(rest), _ => 0 }", 2},
+		{"match ([2, 3]) { [1, ...rest] => len(rest), _ => 0 }", 0},
+		{`match ({"kind": "square", "side": 3}) { {kind: "circle", r} => r, {kind: "square", side} => side * side }`, 9},
+		{"match (5) { x if x > 10 => 1, x if x > 1 => 2, _ => 3 }", 2},
+		{"let x = 1; match (7) { x => x }; x", 1},
+		{"match (1) { 2 => 2 }", nil},
+		{"match ([1]) { {a} => a }", nil},
+		{"let f = fn(x) { match (x) { 1 => 1 } }; f(2)", nil},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.SetNullOnFailedMatch(true)
+
+		evaluated := Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		integer, ok := tt.expected.(int)
+
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestPipelineOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[1, 2, 3] |> len", 3},
+		{"[1, 2] |> push(3) |> len", 3},
+		{"let double = fn(x) { x * 2 }; 5 |> double", 10},
+		{"let sub = fn(a, b) { a - b }; 10 |> sub(3)", 7},
+		{"let add = fn(a, b) { a + b }; let double = fn(x) { x * 2 }; 1 |> add(2) |> double", 6},
+		{"let sub = fn(a, b = 1) { a - b }; 10 |> sub(b: 4)", 6},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let double = x => x * 2; double(4)", 8},
+		{"let add = (a, b = 10) => a + b; add(1)", 11},
+		{"let count = (...rest) => len(rest); count(1, 2, 3)", 3},
+		{"let adder = x => y => x + y; adder(1)(2)", 3},
+		{"let apply = fn(f, x) { f(x) }; apply(x => x - 1, 5)", 4},
+		{"5 |> (x => x * 3)", 15},
+		{"match (4) { n if (n > 3) => (() => n * 2)() }", 8},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	arrow := testEval("(x, y = 2) => x + y")
+	literal := testEval("fn(x, y = 2) { x + y }")
+	if arrow.Inspect() != literal.Inspect() {
+		t.Errorf("arrow function inspected differently. Want %q. Got %q", literal.Inspect(), arrow.Inspect())
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x) { x + 2; };"
 
@@ -280,6 +742,52 @@ func TestFunctionApplication(t *testing.T) {
 	}
 }
 
+func TestFunctionParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let f = fn(a, b = 10) { a + b }; f(1)", 11},
+		{"let f = fn(a, b = 10) { a + b }; f(1, 2)", 3},
+		{"let f = fn(a, b = a * 2) { a + b }; f(3)", 9},
+		{"let n = 0; let f = fn(a = n) { a }; n = 5; f()", 5},
+		{"let f = fn(a, ...rest) { len(rest) }; f(1, 2, 3)", 2},
+		{"let f = fn(a, ...rest) { len(rest) }; f(1)", 0},
+		{"let f = fn(...rest) { rest }; f(1, 2)", []int64{1, 2}},
+		{"let f = fn(a, b, c) { a * 100 + b * 10 + c }; f(...[1, 2, 3])", 123},
+		{"let f = fn(a, b, c) { a * 100 + b * 10 + c }; f(1, ...[2], ...[], 3)", 123},
+		{"let f = fn(a, b = 2, c = 3) { a * 100 + b * 10 + c }; f(1, c: 5)", 125},
+		{"let f = fn(a, b) { a - b }; f(b: 1, a: 5)", 4},
+		{"let f = fn(a, b, ...rest) { rest }; f(...[1, 2, 3, 4])", []int64{3, 4}},
+		{"let a = [2, 3]; [1, ...a, 4]", []int64{1, 2, 3, 4}},
+		{"fn f(a, b = 1) { a + b }; f(1)", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case []int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. Got %T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong number of elements. Want %d. Got %d", len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, el := range expected {
+				testIntegerObject(t, array.Elements[i], el)
+			}
+		}
+	}
+}
+
 func TestClosures(t *testing.T) {
 	// newAdder here is a higher-order function. Higher-order functions are functions that either
 	// return other functions or receive them as arguments.
@@ -301,10 +809,17 @@ func TestBuiltinFunctions(t *testing.T) {
 		expected interface{}
 	}{
 		{`len("")`, 0},
+		{`freeze()`, "wrong number of arguments. Got 0. Want 1"},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
 		{`len(1)`, "argument to `len` not supported. Got INTEGER"},
-		{`len("one", "two")`, "wrong number of arguments. Got 2. Want 1"},
+		{`len("one", "two")`, "unknown unit for `len`: \"two\". Want \"bytes\" or \"chars\""},
+		{`len("one", "two", "three")`, "wrong number of arguments. Got 3. Want 1 or 2"},
+		{`len("héllo")`, 6},
+		{`len("héllo", "bytes")`, 6},
+		{`len("héllo", "chars")`, 5},
+		{`len("こんにちは", "chars")`, 5},
+		{`len([1], "chars")`, "second argument to `len` only supported for STRING. Got ARRAY"},
 		{`len([1, 2, 3])`, 3},
 		{`len([])`, 0},
 		{`first([1, 2, 3])`, 1},
@@ -317,6 +832,17 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`rest([])`, nil},
 		{`push([], 1)`, []int{1}},
 		{`push(1, 1)`, "argument to `push` must be ARRAY. Got INTEGER"},
+		{`int(3.9)`, 3},
+		{`int(-3.9)`, -3},
+		{`int(7)`, 7},
+		{`int("42")`, 42},
+		{`int("4.2")`, `could not parse "4.2" as integer`},
+		{`int([])`, "argument to `int` not supported. Got ARRAY"},
+		{`float(2)`, 2.0},
+		{`float(2.5)`, 2.5},
+		{`float("1e2")`, 100.0},
+		{`float("abc")`, `could not parse "abc" as float`},
+		{`float(true)`, "argument to `float` not supported. Got BOOLEAN"},
 		{`puts("hello", "world!")`, nil},
 	}
 
@@ -326,6 +852,8 @@ func TestBuiltinFunctions(t *testing.T) {
 		switch expected := tt.expected.(type) {
 		case int:
 			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
 		case nil:
 			testNullObject(t, evaluated)
 		case string:
@@ -392,7 +920,9 @@ func TestArrayIndexExpressions(t *testing.T) {
 		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
 		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
 		{"[1, 2, 3][3]", nil},
-		{"[1, 2, 3][-1]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", nil},
 	}
 
 	for _, tt := range tests {
@@ -407,6 +937,64 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4][1:3]", []int64{2, 3}},
+		{"[1, 2, 3, 4][:2]", []int64{1, 2}},
+		{"[1, 2, 3, 4][2:]", []int64{3, 4}},
+		{"[1, 2, 3, 4][:]", []int64{1, 2, 3, 4}},
+		{"[1, 2, 3, 4][-2:]", []int64{3, 4}},
+		{"[1, 2, 3, 4][:-1]", []int64{1, 2, 3}},
+		{"[1, 2, 3, 4][3:1]", []int64{}},
+		{"[1, 2, 3, 4][4:]", []int64{}},
+		{"[1, 2, 3, 4][5:]", nil},
+		{"[1, 2, 3, 4][:5]", nil},
+		{"[1, 2, 3, 4][-5:]", nil},
+		{"let a = [1, 2, 3]; let b = a[1:]; b[0] = 9; a", []int64{1, 2, 3}},
+		{`"héllo"[1:3]`, "él"},
+		{`"hello"[-3:]`, "llo"},
+		{`"hello"[:0]`, ""},
+		{`"hello"[:9]`, nil},
+		{`"héllo"[1]`, "é"},
+		{`"hello"[5]`, nil},
+		{`"hello"[-1]`, "o"},
+		{`"héllo"[-4]`, "é"},
+		{`"hello"[-6]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array for %q. Got %T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong number of elements for %q. Want %d. Got %d", tt.input, len(expected), len(array.Elements))
+				continue
+			}
+
+			for i, el := range expected {
+				testIntegerObject(t, array.Elements[i], el)
+			}
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok || str.Value != expected {
+				t.Errorf("wrong string for %q. Want %q. Got %+v", tt.input, expected, evaluated)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
 func TestHashLiterals(t *testing.T) {
 	input := `let two = "two";
 {
@@ -500,6 +1088,22 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+
+	if !ok {
+		t.Errorf("object is not Float. Got %T (+%v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. Got %g. Want %g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
 	result, ok := obj.(*object.Boolean)
 
@@ -60,7 +60,7 @@ func ExpandMacros(program *ast.Program, env *object.Environment) ast.Node {
 // Just checking if we have a LetStatement with a MacroLiteral
 func isMacroDefined(node ast.Statement) bool {
 	letStatement, ok := node.(*ast.LetStatement)
-	if !ok {
+	if !ok || letStatement.Name == nil {
 		return false
 	}
 
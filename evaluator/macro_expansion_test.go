@@ -92,6 +92,11 @@ func TestExpandMacros(t *testing.T) {
             unless(10 > 5, puts("not greater"), puts("greater"));`,
 			`if (!(10 > 5)) { puts("not greater") } else { puts("greater") }`,
 		},
+		{
+			`let reverse = macro(a, b) { quote(unquote(b) - unquote(a)); };
+            2 + 2 |> reverse(10 - 5);`,
+			`(10 - 5) - (2 + 2)`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,6 +55,14 @@ func convertObjectToASTNode(obj object.Object) ast.Node {
 
 		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
 
+	case *object.Float:
+		t := token.Token{
+			Type:    token.FLOAT,
+			Literal: obj.Inspect(),
+		}
+
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}
+
 	case *object.Boolean:
 		var t token.Token
 		if obj.Value {
@@ -0,0 +1,96 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/akyrey/monkey-programming-language/token"
+)
+
+type ErrorKind string
+
+const (
+	UNEXPECTED_CHARACTER = "UNEXPECTED_CHARACTER"
+	BAD_NUMBER           = "BAD_NUMBER"
+	BAD_ESCAPE           = "BAD_ESCAPE"
+	UNTERMINATED_STRING  = "UNTERMINATED_STRING"
+	UNTERMINATED_COMMENT = "UNTERMINATED_COMMENT"
+	READ_ERROR           = "READ_ERROR"
+)
+
+// A diagnostic found while lexing. The lexer reports it and keeps going, so a single run can find many of them
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Pos     token.Position // where the problem starts
+	End     token.Position // right after the offending text
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
+func (l *Lexer) error(kind ErrorKind, pos, end token.Position, format string, a ...interface{}) {
+	l.errors = append(l.errors, &Error{Kind: kind, Message: fmt.Sprintf(format, a...), Pos: pos, End: end})
+}
+
+// Returns why literal isn't a valid number, or an empty string if it is.
+// We rely on strconv, which is what the parser uses to get the value, and only look at syntax errors:
+// values that don't fit in 64 bits are reported by the parser
+func validateNumber(tokenType token.TokenType, literal string) string {
+	// strconv would read 010 as an octal number, but octal numbers are written 0o10
+	if tokenType == token.INT && HasLeadingZero(literal) {
+		return fmt.Sprintf("leading zeros are not allowed in decimal literal %q, use 0o for octal", literal)
+	}
+
+	var err error
+	if tokenType == token.FLOAT {
+		_, err = strconv.ParseFloat(literal, 64)
+	} else {
+		_, err = strconv.ParseInt(literal, 0, 64)
+	}
+
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return ""
+	}
+
+	lower := strings.ToLower(literal)
+	if tokenType == token.INT && len(lower) >= 2 && lower[0] == '0' && isBasePrefix(rune(lower[1])) {
+		base, name := 16, "hexadecimal"
+		switch lower[1] {
+		case 'o':
+			base, name = 8, "octal"
+		case 'b':
+			base, name = 2, "binary"
+		}
+
+		digits := literal[2:]
+		if strings.Trim(digits, "_") == "" {
+			return fmt.Sprintf("%s literal %q has no digits", name, literal)
+		}
+
+		for _, ch := range digits {
+			if ch != '_' && !isDigitOfBase(ch, base) {
+				return fmt.Sprintf("invalid digit %q in %s literal %q", ch, name, literal)
+			}
+		}
+	}
+
+	if strings.Contains(literal, "_") {
+		return fmt.Sprintf("'_' must separate successive digits in number literal %q", literal)
+	}
+
+	return fmt.Sprintf("malformed number literal %q", literal)
+}
+
+// Whether an integer literal is a decimal number starting with a zero, like 010 or 0_1
+func HasLeadingZero(literal string) bool {
+	return len(literal) > 1 && literal[0] == '0' && !isBasePrefix(rune(literal[1]))
+}
+
+func isDigitOfBase(ch rune, base int) bool {
+	value, err := strconv.ParseInt(string(ch), base, 64)
+	return err == nil && value < int64(base)
+}
@@ -1,77 +1,463 @@
 package lexer
 
-import "github.com/akyrey/monkey-programming-language/token"
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/akyrey/monkey-programming-language/token"
+)
+
+// The lexer reads its input from a buffered reader, so that big scripts or pipes can be lexed incrementally
+// without loading them into memory first. Only the text of the token being lexed is kept around, in window
 type Lexer struct {
-	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	reader       *bufio.Reader
+	done         bool   // the reader has nothing left to give us (EOF or read error)
+	window       []byte // raw input read since windowStart, it's used to build the literals of tokens
+	windowStart  int    // offset of the first byte in window
+	position     int    // current position in input (points to current char)
+	readPosition int    // current reading position in input (after current char)
+	ch           rune   // current char under examination
+	eof          bool   // the current char is past the end of the input
+	peek         rune   // the char after the current one, valid when hasPeek is set
+	peekWidth    int
+	peekOK       bool // false if there is no char after the current one
+	hasPeek      bool
+	line         int // line of the current char, starting from 1
+	column       int // column of the current char, starting from 1
+	errors       []*Error
+	templates    []template // template strings whose interpolations we are lexing, innermost last
+}
+
+// Keeps track of an open ${ interpolation inside a string, so we know which } gets us back into the string
+type template struct {
+	start  token.Position // position of the opening quote, used to report unterminated strings
+	braces int            // number of { opened inside the interpolation and not closed yet
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFromReader(strings.NewReader(input))
+}
+
+// Lexes the content of r as it's read, producing the same tokens and positions New would produce for the same input
+func NewFromReader(r io.Reader) *Lexer {
+	l := &Lexer{reader: bufio.NewReader(r), line: 1, column: 1}
+	l.readChar()
+	return l
+}
+
+// Lexes input as if it started at start inside a bigger text, so the positions of the tokens refer to that text.
+// This lets callers re-lex just a part of a file they already lexed
+func NewAt(input string, start token.Position) *Lexer {
+	l := &Lexer{
+		reader:       bufio.NewReader(strings.NewReader(input)),
+		windowStart:  start.Offset,
+		position:     start.Offset,
+		readPosition: start.Offset,
+		line:         start.Line,
+		column:       start.Column,
+	}
 	l.readChar()
 	return l
 }
 
 /**
- * Give us next character and advance our position in the input string
- * TODO: only ASCII supported at the moment
+ * Give us next character and advance our position in the input
+ * The input is decoded as UTF-8, so a single char may span multiple bytes
  */
 func (l *Lexer) readChar() {
-	// If we reached the end of the input, we use NUL character
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
+	// Move line and column past the char we are leaving behind (if any)
+	if l.position < l.readPosition && !l.eof {
+		if l.ch == '\n' {
+			l.line += 1
+			l.column = 1
+		} else {
+			l.column += 1
+		}
+	}
+
+	var ch rune
+	var width int
+	var ok bool
+
+	if l.hasPeek {
+		ch, width, ok = l.peek, l.peekWidth, l.peekOK
+		l.hasPeek = false
 	} else {
-		l.ch = l.input[l.readPosition]
+		ch, width, ok = l.readRune()
 	}
+
+	// If we reached the end of the input, we use NUL character and stop advancing
+	if !ok {
+		l.ch = 0
+		l.eof = true
+		l.position = l.readPosition
+		return
+	}
+
+	l.ch = ch
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += width
+}
+
+// Reads the next char from the reader, appending its bytes to the window
+func (l *Lexer) readRune() (rune, int, bool) {
+	if l.done {
+		return 0, 0, false
+	}
+
+	// Near the end of the input we get less than UTFMax bytes together with the error
+	buf, err := l.reader.Peek(utf8.UTFMax)
+	if len(buf) == 0 {
+		if err != nil && err != io.EOF {
+			l.error(READ_ERROR, l.currentPosition(), l.currentPosition(), "could not read input: %s", err)
+		}
+
+		l.done = true
+		return 0, 0, false
+	}
+
+	ch, width := utf8.DecodeRune(buf)
+	l.window = append(l.window, buf[:width]...)
+	l.reader.Discard(width)
+
+	return ch, width, true
+}
+
+// Returns the input from offset up to the current char (excluded)
+func (l *Lexer) slice(offset int) string {
+	return string(l.window[offset-l.windowStart : l.position-l.windowStart])
+}
+
+// Forgets the input before the current char, called at the start of every token
+func (l *Lexer) discardWindow() {
+	l.window = append(l.window[:0], l.window[l.position-l.windowStart:]...)
+	l.windowStart = l.position
+}
+
+// Returns the position of the char currently under examination
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{Offset: l.position, Line: l.line, Column: l.column}
+}
+
+// Returns the position right after the char currently under examination
+func (l *Lexer) nextPosition() token.Position {
+	if l.eof {
+		return l.currentPosition()
+	}
+
+	if l.ch == '\n' {
+		return token.Position{Offset: l.readPosition, Line: l.line + 1, Column: 1}
+	}
+
+	return token.Position{Offset: l.readPosition, Line: l.line, Column: l.column + 1}
 }
 
 /**
 * Similar to readChar, but doesn't increment l.position
  */
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+func (l *Lexer) peekChar() rune {
+	if !l.hasPeek {
+		l.peek, l.peekWidth, l.peekOK = l.readRune()
+		l.hasPeek = true
+	}
+
+	if !l.peekOK {
 		return 0
 	}
 
-	return l.input[l.readPosition]
+	return l.peek
+}
+
+// Whether the current char is the last one of the input
+func (l *Lexer) peekAtEOF() bool {
+	l.peekChar()
+	return !l.peekOK
 }
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 
-	for isLetter(l.ch) {
+	l.readChar()
+	for isIdentifierPart(l.ch) {
 		l.readChar()
 	}
 
-	return l.input[position:l.position]
+	return l.slice(position)
 }
 
-func (l *Lexer) readNumber() string {
+// Reads integers and floats. A number is a float if it has a fractional part (1.5) or an exponent (1e3, 2.5E-2)
+// Integers can also be written in hexadecimal (0xFF), octal (0o755) or binary (0b1010), and digits can be
+// separated by underscores (1_000_000). Here we only find where the number ends, digits are checked by validateNumber
+func (l *Lexer) readNumber() (token.TokenType, string) {
 	position := l.position
+	tokenType := token.TokenType(token.INT)
+
+	if l.ch == '0' && isBasePrefix(l.peekChar()) {
+		l.readChar()
+		l.readChar()
+
+		// We also consume letters, so that malformed numbers like 0xFG or 0b102 are reported as a whole
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.readChar()
+		}
+
+		return tokenType, l.slice(position)
+	}
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.readDigits()
+	}
+
+	if (l.ch == 'e' || l.ch == 'E') && (isDigit(l.peekChar()) || l.peekChar() == '+' || l.peekChar() == '-') {
+		tokenType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		l.readDigits()
+	}
+
+	return tokenType, l.slice(position)
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+// Errors found while lexing the input, e.g. unterminated strings or invalid escape sequences
+func (l *Lexer) Errors() []*Error {
+	return l.errors
+}
+
+// We can't just rely on l.ch being 0, since a NUL char could be part of the input
+func (l *Lexer) atEOF() bool {
+	return l.eof
+}
+
+// Reads the content of a string literal decoding its escape sequences, starting from the opening quote
+// or from the } closing an interpolation. It stops on the closing quote, returning false, or on the $ of
+// a ${ interpolation, returning true and leaving l.ch on the {. On EOF an error is reported
+func (l *Lexer) readString(start token.Position) (string, bool) {
+	var out strings.Builder
+
+	for {
+		l.readChar()
+
+		switch {
+		case l.atEOF():
+			l.error(UNTERMINATED_STRING, start, l.currentPosition(), "unterminated string literal")
+			return out.String(), false
+		case l.ch == '"':
+			return out.String(), false
+		case l.ch == '$' && l.peekChar() == '{':
+			l.readChar()
+			return out.String(), true
+		case l.ch == '\\':
+			l.readEscape(&out)
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// Strings with interpolations are split in parts: a TEMPLATE_HEAD up to the first ${, a TEMPLATE_MIDDLE
+// between two interpolations and a TEMPLATE_TAIL up to the closing quote. The tokens of the embedded
+// expressions are lexed in between, like any other token
+func (l *Lexer) readTemplatePart(start token.Position, first bool) token.Token {
+	literal, interpolation := l.readString(start)
+
+	switch {
+	case interpolation && first:
+		l.templates = append(l.templates, template{start: start})
+		return token.Token{Type: token.TEMPLATE_HEAD, Literal: literal}
+	case interpolation:
+		l.templates = append(l.templates, template{start: start})
+		return token.Token{Type: token.TEMPLATE_MIDDLE, Literal: literal}
+	case first:
+		return token.Token{Type: token.STRING, Literal: literal}
+	default:
+		return token.Token{Type: token.TEMPLATE_TAIL, Literal: literal}
+	}
+}
 
-	for isDigit(l.ch) {
+// Reads a string delimited by backticks, starting from the opening one. Its content is taken as is:
+// it can span multiple lines and backslashes or ${ have no special meaning. Like Go we drop carriage returns,
+// so the value doesn't depend on the line endings of the file
+func (l *Lexer) readRawString(start token.Position) string {
+	for {
 		l.readChar()
+
+		if l.atEOF() {
+			l.error(UNTERMINATED_STRING, start, l.currentPosition(), "unterminated raw string literal")
+			break
+		}
+
+		if l.ch == '`' {
+			break
+		}
 	}
 
-	return l.input[position:l.position]
+	return strings.ReplaceAll(l.slice(start.Offset+1), "\r", "")
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// Reads a raw string delimited by three double quotes, starting from the last opening quote.
+// Quotes inside it don't need escaping, only three in a row close the string
+func (l *Lexer) readHeredoc(start token.Position) string {
+	var out strings.Builder
+	quotes := 0
 
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+
+		if l.atEOF() {
+			l.error(UNTERMINATED_STRING, start, l.currentPosition(), "unterminated heredoc string literal")
 			break
 		}
+
+		if l.ch == '"' {
+			quotes += 1
+			if quotes == 3 {
+				return dedent(out.String())
+			}
+			continue
+		}
+
+		out.WriteString(strings.Repeat(`"`, quotes))
+		quotes = 0
+		if l.ch != '\r' {
+			out.WriteRune(l.ch)
+		}
 	}
 
-	return l.input[position:l.position]
+	out.WriteString(strings.Repeat(`"`, quotes))
+	return dedent(out.String())
+}
+
+// When the opening quotes of a heredoc end their line, the content is laid out as a block:
+// that first line break is dropped, as is the line of the closing quotes if there's only whitespace before them,
+// and the indentation common to all the lines (closing one included) is removed. Blank lines don't count.
+// A heredoc that starts on the same line as its opening quotes is kept as is
+func dedent(s string) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) < 2 || !isBlank(lines[0]) {
+		return s
+	}
+	lines = lines[1:]
+
+	last := lines[len(lines)-1]
+	closing := isBlank(last)
+	if closing {
+		lines = lines[:len(lines)-1]
+	}
+
+	prefix, found := "", false
+	if closing {
+		prefix, found = last, true
+	}
+
+	for _, line := range lines {
+		if isBlank(line) {
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			prefix, found = indent, true
+			continue
+		}
+
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	for i, line := range lines {
+		if isBlank(line) {
+			lines[i] = ""
+		} else {
+			lines[i] = line[len(prefix):]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimLeft(line, " \t") == ""
+}
+
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$',
+}
+
+// Called while l.ch is the backslash, it leaves l.ch on the last char of the escape sequence
+func (l *Lexer) readEscape(out *strings.Builder) {
+	start := l.currentPosition()
+
+	// Leave the EOF to readString, so it can report the unterminated string
+	if l.peekAtEOF() {
+		return
+	}
+
+	l.readChar()
+
+	if ch, ok := escapes[l.ch]; ok {
+		out.WriteRune(ch)
+		return
+	}
+
+	if l.ch != 'u' {
+		l.error(BAD_ESCAPE, start, l.nextPosition(), "unknown escape sequence: \\%c", l.ch)
+		out.WriteRune(l.ch)
+		return
+	}
+
+	// Unicode escapes have the form \u{1F600}, with 1 to 6 hexadecimal digits
+	if l.peekChar() != '{' {
+		l.error(BAD_ESCAPE, start, l.nextPosition(), "invalid unicode escape sequence: missing '{'")
+		return
+	}
+	l.readChar()
+
+	var digits strings.Builder
+	for l.peekChar() != '}' && l.peekChar() != '"' && !l.peekAtEOF() {
+		l.readChar()
+		digits.WriteRune(l.ch)
+	}
+
+	if l.peekChar() != '}' {
+		l.error(BAD_ESCAPE, start, l.nextPosition(), "invalid unicode escape sequence: missing '}'")
+		return
+	}
+	l.readChar()
+
+	value, err := strconv.ParseUint(digits.String(), 16, 32)
+	if err != nil || digits.Len() > 6 {
+		l.error(BAD_ESCAPE, start, l.nextPosition(), "invalid unicode escape sequence: \\u{%s}", digits.String())
+		return
+	}
+
+	if r := rune(value); utf8.ValidRune(r) {
+		out.WriteRune(r)
+	} else {
+		l.error(BAD_ESCAPE, start, l.nextPosition(), "invalid unicode code point: \\u{%s}", digits.String())
+	}
 }
 
 func (l *Lexer) skipWhitespace() {
@@ -80,19 +466,87 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
+// Skips whitespace and comments, returning the comments so they can be attached to the next token
+func (l *Lexer) skipTrivia() []token.Trivia {
+	var trivia []token.Trivia
+
+	for {
+		l.skipWhitespace()
+
+		if l.ch != '/' || (l.peekChar() != '/' && l.peekChar() != '*') {
+			return trivia
+		}
+
+		if l.peekChar() == '/' {
+			trivia = append(trivia, l.readLineComment())
+		} else {
+			trivia = append(trivia, l.readBlockComment())
+		}
+	}
+}
+
+// A line comment goes on until the end of the line, the newline itself is not part of it
+func (l *Lexer) readLineComment() token.Trivia {
+	pos := l.currentPosition()
+
+	for l.ch != '\n' && !l.atEOF() {
+		l.readChar()
+	}
+
+	return token.Trivia{
+		Type:    token.LINE_COMMENT,
+		Literal: l.slice(pos.Offset),
+		Pos:     pos,
+		End:     l.currentPosition(),
+	}
+}
+
+// Block comments can span multiple lines, but they can't be nested
+func (l *Lexer) readBlockComment() token.Trivia {
+	pos := l.currentPosition()
+
+	// Skip the opening /*
+	l.readChar()
+	l.readChar()
+
+	for !(l.ch == '*' && l.peekChar() == '/') {
+		if l.atEOF() {
+			l.error(UNTERMINATED_COMMENT, pos, l.currentPosition(), "unterminated block comment")
+			break
+		}
+
+		l.readChar()
+	}
+
+	// Skip the closing */
+	if !l.atEOF() {
+		l.readChar()
+		l.readChar()
+	}
+
+	return token.Trivia{
+		Type:    token.BLOCK_COMMENT,
+		Literal: l.slice(pos.Offset),
+		Pos:     pos,
+		End:     l.currentPosition(),
+	}
+}
+
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	l.skipWhitespace()
+	l.discardWindow()
+	trivia := l.skipTrivia()
+	pos := l.currentPosition()
 
 	switch l.ch {
 	case '=':
-		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			literal := string(ch) + string(l.ch)
-			tok = token.Token{Type: token.EQ, Literal: literal}
-		} else {
+		switch l.peekChar() {
+		case '=':
+			tok = l.newTwoCharToken(token.EQ)
+		case '>':
+			tok = l.newTwoCharToken(token.ARROW)
+		default:
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
@@ -101,10 +555,7 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.MINUS, l.ch)
 	case '!':
 		if l.peekChar() == '=' {
-			ch := l.ch
-			l.readChar()
-			literal := string(ch) + string(l.ch)
-			tok = token.Token{Type: token.NOT_EQ, Literal: literal}
+			tok = l.newTwoCharToken(token.NOT_EQ)
 		} else {
 			tok = newToken(token.BANG, l.ch)
 		}
@@ -112,16 +563,64 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.ASTERISK, l.ch)
 	case '/':
 		tok = newToken(token.SLASH, l.ch)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		switch l.peekChar() {
+		case '<':
+			tok = l.newTwoCharToken(token.SHIFT_LEFT)
+		case '=':
+			tok = l.newTwoCharToken(token.LT_EQ)
+		default:
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		switch l.peekChar() {
+		case '>':
+			tok = l.newTwoCharToken(token.SHIFT_RIGHT)
+		case '=':
+			tok = l.newTwoCharToken(token.GT_EQ)
+		default:
+			tok = newToken(token.GT, l.ch)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			tok = l.newTwoCharToken(token.AND)
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '|':
+		switch l.peekChar() {
+		case '|':
+			tok = l.newTwoCharToken(token.OR)
+		case '>':
+			tok = l.newTwoCharToken(token.PIPELINE)
+		default:
+			tok = newToken(token.PIPE, l.ch)
+		}
+	case '^':
+		tok = newToken(token.CARET, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ':':
 		tok = newToken(token.COLON, l.ch)
+	case '.':
+		// Dots are only valid as part of a number or of an ellipsis
+		if l.peekChar() != '.' {
+			return l.skipUnexpected(pos, trivia, "unexpected character %q", l.ch)
+		}
+
+		l.readChar()
+		if l.peekChar() != '.' {
+			return l.skipUnexpected(pos, trivia, "unexpected characters %q", "..")
+		}
+
+		l.readChar()
+		tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -131,13 +630,50 @@ func (l *Lexer) NextToken() token.Token {
 	case ']':
 		tok = newToken(token.RBRACKET, l.ch)
 	case '{':
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1].braces += 1
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		// This closes the current interpolation, so we go back to reading the string
+		if n := len(l.templates); n > 0 && l.templates[n-1].braces == 0 {
+			start := l.templates[n-1].start
+			l.templates = l.templates[:n-1]
+			tok = l.readTemplatePart(start, false)
+			break
+		}
+
+		if len(l.templates) > 0 {
+			l.templates[len(l.templates)-1].braces -= 1
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		// Two quotes are an empty string, unless a third one opens a heredoc
+		if l.peekChar() == '"' {
+			l.readChar()
+			if l.peekChar() == '"' {
+				l.readChar()
+				tok = token.Token{Type: token.STRING, Literal: l.readHeredoc(pos)}
+			} else {
+				tok = token.Token{Type: token.STRING, Literal: ""}
+			}
+			break
+		}
+
+		tok = l.readTemplatePart(pos, true)
+	case '`':
+		tok = token.Token{Type: token.STRING, Literal: l.readRawString(pos)}
 	case 0:
+		// A NUL char in the middle of the input is not the end of it
+		if !l.atEOF() {
+			return l.skipUnexpected(pos, trivia, "unexpected character %q", l.ch)
+		}
+
+		for _, template := range l.templates {
+			l.error(UNTERMINATED_STRING, template.start, pos, "unterminated string interpolation")
+		}
+		l.templates = nil
+
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
@@ -145,30 +681,72 @@ func (l *Lexer) NextToken() token.Token {
 			// We need to return here because we already advanced our readPosition in readIdentifier
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			return l.finishToken(tok, pos, trivia)
 		}
 
 		if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+			tok.Type, tok.Literal = l.readNumber()
+			if msg := validateNumber(tok.Type, tok.Literal); msg != "" {
+				l.error(BAD_NUMBER, pos, l.currentPosition(), "%s", msg)
+			}
+			return l.finishToken(tok, pos, trivia)
 		}
 
-		tok = newToken(token.ILLEGAL, l.ch)
+		return l.skipUnexpected(pos, trivia, "unexpected character %q", l.ch)
 	}
 
 	l.readChar()
+	return l.finishToken(tok, pos, trivia)
+}
+
+// Instead of producing an ILLEGAL token we report the chars from pos to the current one and go on with the next token,
+// so the parser doesn't have to deal with them. The trivia we found is not lost
+func (l *Lexer) skipUnexpected(pos token.Position, trivia []token.Trivia, format string, a ...interface{}) token.Token {
+	l.error(UNEXPECTED_CHARACTER, pos, l.nextPosition(), format, a...)
+	l.readChar()
+
+	next := l.NextToken()
+	next.Trivia = append(trivia, next.Trivia...)
+	return next
+}
+
+// Stamps the token with its position and the trivia that precedes it
+// It must be called when the lexer already advanced past the token
+func (l *Lexer) finishToken(tok token.Token, pos token.Position, trivia []token.Trivia) token.Token {
+	tok.Pos = pos
+	tok.End = l.currentPosition()
+	tok.Trivia = trivia
+
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// Consumes the current char and the next one, using both as literal of the token
+func (l *Lexer) newTwoCharToken(tokenType token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+
+	return token.Token{Type: tokenType, Literal: string(ch) + string(l.ch)}
 }
 
-func isDigit(ch byte) bool {
+// Any Unicode letter can be part of an identifier
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
+}
+
+// After the first letter, an identifier can also have combining marks, like the vowel signs of नमस्ते
+// or the accent of a decomposed é
+func isIdentifierPart(ch rune) bool {
+	return isLetter(ch) || unicode.In(ch, unicode.Mn, unicode.Mc)
+}
+
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
+
+func isBasePrefix(ch rune) bool {
+	return ch == 'x' || ch == 'X' || ch == 'o' || ch == 'O' || ch == 'b' || ch == 'B'
+}
@@ -1,7 +1,12 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/akyrey/monkey-programming-language/token"
 )
@@ -15,7 +20,7 @@ let add = fn(x, y) {
 };
 
 let result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -173,3 +178,852 @@ macro(x, y) { x + y; };
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5;
+if (x >= "ab") {
+  x
+}`
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.LET, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Position{Offset: 6, Line: 1, Column: 7}, token.Position{Offset: 7, Line: 1, Column: 8}},
+		{token.INT, token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 9, Line: 1, Column: 10}},
+		{token.SEMICOLON, token.Position{Offset: 9, Line: 1, Column: 10}, token.Position{Offset: 10, Line: 1, Column: 11}},
+		{token.IF, token.Position{Offset: 11, Line: 2, Column: 1}, token.Position{Offset: 13, Line: 2, Column: 3}},
+		{token.LPAREN, token.Position{Offset: 14, Line: 2, Column: 4}, token.Position{Offset: 15, Line: 2, Column: 5}},
+		{token.IDENT, token.Position{Offset: 15, Line: 2, Column: 5}, token.Position{Offset: 16, Line: 2, Column: 6}},
+		{token.GT_EQ, token.Position{Offset: 17, Line: 2, Column: 7}, token.Position{Offset: 19, Line: 2, Column: 9}},
+		{token.STRING, token.Position{Offset: 20, Line: 2, Column: 10}, token.Position{Offset: 24, Line: 2, Column: 14}},
+		{token.RPAREN, token.Position{Offset: 24, Line: 2, Column: 14}, token.Position{Offset: 25, Line: 2, Column: 15}},
+		{token.LBRACE, token.Position{Offset: 26, Line: 2, Column: 16}, token.Position{Offset: 27, Line: 2, Column: 17}},
+		{token.IDENT, token.Position{Offset: 30, Line: 3, Column: 3}, token.Position{Offset: 31, Line: 3, Column: 4}},
+		{token.RBRACE, token.Position{Offset: 32, Line: 4, Column: 1}, token.Position{Offset: 33, Line: 4, Column: 2}},
+		{token.EOF, token.Position{Offset: 33, Line: 4, Column: 2}, token.Position{Offset: 33, Line: 4, Column: 2}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - position wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end position wrong. expected=%+v, got=%+v", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	// The last café is decomposed, with the accent as a combining mark
+	input := `let café = "héllo, 世界";
+let 名前 = café;
+` + "let नमस्ते = cafe\u0301;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedPos     token.Position
+	}{
+		{token.LET, "let", token.Position{Offset: 0, Line: 1, Column: 1}},
+		{token.IDENT, "café", token.Position{Offset: 4, Line: 1, Column: 5}},
+		{token.ASSIGN, "=", token.Position{Offset: 10, Line: 1, Column: 10}},
+		{token.STRING, "héllo, 世界", token.Position{Offset: 12, Line: 1, Column: 12}},
+		{token.SEMICOLON, ";", token.Position{Offset: 28, Line: 1, Column: 23}},
+		{token.LET, "let", token.Position{Offset: 30, Line: 2, Column: 1}},
+		{token.IDENT, "名前", token.Position{Offset: 34, Line: 2, Column: 5}},
+		{token.ASSIGN, "=", token.Position{Offset: 41, Line: 2, Column: 8}},
+		{token.IDENT, "café", token.Position{Offset: 43, Line: 2, Column: 10}},
+		{token.SEMICOLON, ";", token.Position{Offset: 48, Line: 2, Column: 14}},
+		{token.LET, "let", token.Position{Offset: 50, Line: 3, Column: 1}},
+		{token.IDENT, "नमस्ते", token.Position{Offset: 54, Line: 3, Column: 5}},
+		{token.ASSIGN, "=", token.Position{Offset: 73, Line: 3, Column: 12}},
+		{token.IDENT, "cafe\u0301", token.Position{Offset: 75, Line: 3, Column: 14}},
+		{token.SEMICOLON, ";", token.Position{Offset: 81, Line: 3, Column: 19}},
+		{token.EOF, "", token.Position{Offset: 82, Line: 3, Column: 20}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos != tt.expectedPos {
+			t.Fatalf("tests[%d] - position wrong. expected=%+v, got=%+v", i, tt.expectedPos, tok.Pos)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+	}{
+		{`"foo\nbar"`, "foo\nbar"},
+		{`"tab\there"`, "tab\there"},
+		{`"carriage\rreturn"`, "carriage\rreturn"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"nul\0"`, "nul\x00"},
+		{`"\u{48}\u{49}"`, "HI"},
+		{`"\u{1F600}"`, "\U0001F600"},
+		{`"\u{e9}t\u{E9}"`, "été"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong. expected=%q, got=%q", token.STRING, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("literal wrong. expected=%q, got=%q", tt.expectedLiteral, tok.Literal)
+		}
+
+		if len(l.Errors()) != 0 {
+			t.Errorf("unexpected lexer errors for %s: %v", tt.input, l.Errors())
+		}
+
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("expected EOF after string, got=%q", next.Type)
+		}
+	}
+}
+
+func TestRawStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+	}{
+		{"``", ""},
+		{"`C:\\path\\n`", `C:\path\n`},
+		{"`no ${interpolation} \"here\"`", `no ${interpolation} "here"`},
+		{"`^\\d+$`", `^\d+$`},
+		{"`SELECT *\n  FROM users\n  WHERE id = 1`", "SELECT *\n  FROM users\n  WHERE id = 1"},
+		{"`windows\r\nline endings`", "windows\nline endings"},
+		{`""`, ""},
+		{`"""a "quoted" word"""`, `a "quoted" word`},
+		{`""""""`, ""},
+		{"\"\"\"\n    {\n      \"id\": 1\n    }\n    \"\"\"", "{\n  \"id\": 1\n}"},
+		{"\"\"\"\n    first\n\n      second\n  \"\"\"", "  first\n\n    second"},
+		{"\"\"\"   \n\tone\n\ttwo\"\"\"", "one\ntwo"},
+		{"\"\"\"\n  no\\escapes\\n ${x}\n  \"\"\"", "no\\escapes\\n ${x}"},
+		{"\"\"\"\r\n  crlf\r\n  \"\"\"", "crlf"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != token.STRING {
+			t.Fatalf("tokentype wrong for %q. expected=%q, got=%q", tt.input, token.STRING, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Errorf("literal wrong for %q. expected=%q, got=%q", tt.input, tt.expectedLiteral, tok.Literal)
+		}
+
+		if len(l.Errors()) != 0 {
+			t.Errorf("unexpected lexer errors for %q: %v", tt.input, l.Errors())
+		}
+
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("expected EOF after string %q, got=%q", tt.input, next.Type)
+		}
+	}
+}
+
+func TestRawStringPositions(t *testing.T) {
+	input := "let q = `a\nbc`;\nlet h = \"\"\"\n  x\n  \"\"\";"
+
+	expected := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.LET, token.Position{Offset: 0, Line: 1, Column: 1}, token.Position{Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Offset: 4, Line: 1, Column: 5}, token.Position{Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Position{Offset: 6, Line: 1, Column: 7}, token.Position{Offset: 7, Line: 1, Column: 8}},
+		{token.STRING, token.Position{Offset: 8, Line: 1, Column: 9}, token.Position{Offset: 14, Line: 2, Column: 4}},
+		{token.SEMICOLON, token.Position{Offset: 14, Line: 2, Column: 4}, token.Position{Offset: 15, Line: 2, Column: 5}},
+		{token.LET, token.Position{Offset: 16, Line: 3, Column: 1}, token.Position{Offset: 19, Line: 3, Column: 4}},
+		{token.IDENT, token.Position{Offset: 20, Line: 3, Column: 5}, token.Position{Offset: 21, Line: 3, Column: 6}},
+		{token.ASSIGN, token.Position{Offset: 22, Line: 3, Column: 7}, token.Position{Offset: 23, Line: 3, Column: 8}},
+		{token.STRING, token.Position{Offset: 24, Line: 3, Column: 9}, token.Position{Offset: 37, Line: 5, Column: 6}},
+		{token.SEMICOLON, token.Position{Offset: 37, Line: 5, Column: 6}, token.Position{Offset: 38, Line: 5, Column: 7}},
+		{token.EOF, token.Position{Offset: 38, Line: 5, Column: 7}, token.Position{Offset: 38, Line: 5, Column: 7}},
+	}
+
+	l := New(input)
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos != tt.expectedPos || tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - position wrong. expected=%#v-%#v, got=%#v-%#v", i, tt.expectedPos, tt.expectedEnd, tok.Pos, tok.End)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKind  ErrorKind
+		expectedError string
+	}{
+		{`"unterminated`, UNTERMINATED_STRING, "1:1: unterminated string literal"},
+		{"let x = \"multi\nline", UNTERMINATED_STRING, "1:9: unterminated string literal"},
+		{`"ends with backslash\`, UNTERMINATED_STRING, "1:1: unterminated string literal"},
+		{`"\q"`, BAD_ESCAPE, `1:2: unknown escape sequence: \q`},
+		{`"\u0041"`, BAD_ESCAPE, "1:2: invalid unicode escape sequence: missing '{'"},
+		{`"\u{41"`, BAD_ESCAPE, "1:2: invalid unicode escape sequence: missing '}'"},
+		{`"\u{}"`, BAD_ESCAPE, `1:2: invalid unicode escape sequence: \u{}`},
+		{`"\u{zz}"`, BAD_ESCAPE, `1:2: invalid unicode escape sequence: \u{zz}`},
+		{`"\u{D800}"`, BAD_ESCAPE, `1:2: invalid unicode code point: \u{D800}`},
+		{`"\u{110000}"`, BAD_ESCAPE, `1:2: invalid unicode code point: \u{110000}`},
+		{"let q = `SELECT *\nFROM t", UNTERMINATED_STRING, "1:9: unterminated raw string literal"},
+		{"\"\"\"\n  never closed\n\"\"", UNTERMINATED_STRING, "1:1: unterminated heredoc string literal"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 error for %s, got=%v", tt.input, errors)
+			continue
+		}
+
+		if errors[0].Kind != tt.expectedKind {
+			t.Errorf("error kind wrong. expected=%q, got=%q", tt.expectedKind, errors[0].Kind)
+		}
+
+		if errors[0].Error() != tt.expectedError {
+			t.Errorf("error wrong. expected=%q, got=%q", tt.expectedError, errors[0].Error())
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 5; // trailing comment
+/* block
+   comment */ x / 2;
+/* doc */ /* more */
+`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedTrivia  []token.Trivia
+	}{
+		{token.LET, "let", []token.Trivia{
+			{Type: token.LINE_COMMENT, Literal: "// leading comment", Pos: token.Position{Offset: 0, Line: 1, Column: 1}, End: token.Position{Offset: 18, Line: 1, Column: 19}},
+		}},
+		{token.IDENT, "x", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT, "5", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", []token.Trivia{
+			{Type: token.LINE_COMMENT, Literal: "// trailing comment", Pos: token.Position{Offset: 30, Line: 2, Column: 12}, End: token.Position{Offset: 49, Line: 2, Column: 31}},
+			{Type: token.BLOCK_COMMENT, Literal: "/* block\n   comment */", Pos: token.Position{Offset: 50, Line: 3, Column: 1}, End: token.Position{Offset: 72, Line: 4, Column: 14}},
+		}},
+		{token.SLASH, "/", nil},
+		{token.INT, "2", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.EOF, "", []token.Trivia{
+			{Type: token.BLOCK_COMMENT, Literal: "/* doc */", Pos: token.Position{Offset: 80, Line: 5, Column: 1}, End: token.Position{Offset: 89, Line: 5, Column: 10}},
+			{Type: token.BLOCK_COMMENT, Literal: "/* more */", Pos: token.Position{Offset: 90, Line: 5, Column: 11}, End: token.Position{Offset: 100, Line: 5, Column: 21}},
+		}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if len(tok.Trivia) != len(tt.expectedTrivia) {
+			t.Fatalf("tests[%d] - wrong number of trivia. expected=%d, got=%d (%+v)", i, len(tt.expectedTrivia), len(tok.Trivia), tok.Trivia)
+		}
+
+		for j, trivia := range tt.expectedTrivia {
+			if tok.Trivia[j] != trivia {
+				t.Errorf("tests[%d] - trivia[%d] wrong. expected=%+v, got=%+v", i, j, trivia, tok.Trivia[j])
+			}
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("let x = 1; /* never closed")
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Kind != UNTERMINATED_COMMENT || errors[0].Error() != "1:12: unterminated block comment" {
+		t.Errorf("wrong errors. Got %v", errors)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `5 3.14 0.5 1e10 2.5E-3 6e+2 7 8x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "5"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "1e10"},
+		{token.FLOAT, "2.5E-3"},
+		{token.FLOAT, "6e+2"},
+		{token.INT, "7"},
+		{token.INT, "8"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestIntegerBasesAndBitwiseOperators(t *testing.T) {
+	input := `0xFF 0o755 0b1010 1_000_000 0xFG 0b102 a & b | c ^ ~d << 2 >> 1`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xFF"},
+		{token.INT, "0o755"},
+		{token.INT, "0b1010"},
+		{token.INT, "1_000_000"},
+		{token.INT, "0xFG"},
+		{token.INT, "0b102"},
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.INT, "2"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.INT, "1"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLeadingZeros(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"0", ""},
+		{"0.5", ""},
+		{"0o10", ""},
+		{"010", `1:1: leading zeros are not allowed in decimal literal "010", use 0o for octal`},
+		{"09", `1:1: leading zeros are not allowed in decimal literal "09", use 0o for octal`},
+		{"0_1", `1:1: leading zeros are not allowed in decimal literal "0_1", use 0o for octal`},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		errors := []string{}
+		for _, err := range l.Errors() {
+			errors = append(errors, err.Error())
+		}
+
+		if strings.Join(errors, "\n") != tt.expectedError {
+			t.Errorf("errors wrong for %s. expected=%q, got=%q", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	input := `a <= b >= c && d || e % 2 |> f`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.AND, "&&"},
+		{token.IDENT, "d"},
+		{token.OR, "||"},
+		{token.IDENT, "e"},
+		{token.PERCENT, "%"},
+		{token.INT, "2"},
+		{token.PIPELINE, "|>"},
+		{token.IDENT, "f"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNewFromReaderMatchesNew(t *testing.T) {
+	input := `// Comments, unicode and escapes must survive buffer boundaries
+let café = "héllo\t世界 \u{1F600}";
+let add = fn(x, y) { x + y; };
+/* multi
+   line */
+if (add(1.5, 0xFF) >= 10 && !false) { return [1, 2]; } else { {"foo": "bar"} }
+"unterminated`
+
+	// A one byte reader forces the lexer to refill its buffer in the middle of every multi-byte char
+	readers := map[string]*Lexer{
+		"string reader":   NewFromReader(strings.NewReader(input)),
+		"one byte reader": NewFromReader(iotest.OneByteReader(strings.NewReader(input))),
+		"half reader":     NewFromReader(iotest.HalfReader(strings.NewReader(input))),
+	}
+
+	for name, streaming := range readers {
+		l := New(input)
+
+		for i := 0; ; i++ {
+			expected := l.NextToken()
+			tok := streaming.NextToken()
+
+			if tok.Type != expected.Type || tok.Literal != expected.Literal {
+				t.Fatalf("%s: tokens[%d] wrong. expected=%q (%q), got=%q (%q)", name, i, expected.Type, expected.Literal, tok.Type, tok.Literal)
+			}
+
+			if tok.Pos != expected.Pos || tok.End != expected.End {
+				t.Fatalf("%s: tokens[%d] positions wrong. expected=%+v-%+v, got=%+v-%+v", name, i, expected.Pos, expected.End, tok.Pos, tok.End)
+			}
+
+			if len(tok.Trivia) != len(expected.Trivia) {
+				t.Fatalf("%s: tokens[%d] trivia wrong. expected=%+v, got=%+v", name, i, expected.Trivia, tok.Trivia)
+			}
+
+			for j := range expected.Trivia {
+				if tok.Trivia[j] != expected.Trivia[j] {
+					t.Fatalf("%s: tokens[%d] trivia wrong. expected=%+v, got=%+v", name, i, expected.Trivia[j], tok.Trivia[j])
+				}
+			}
+
+			if expected.Type == token.EOF {
+				break
+			}
+		}
+
+		if fmt.Sprint(streaming.Errors()) != fmt.Sprint(l.Errors()) {
+			t.Errorf("%s: errors wrong. expected=%v, got=%v", name, l.Errors(), streaming.Errors())
+		}
+	}
+}
+
+func TestNewFromReaderError(t *testing.T) {
+	r := iotest.DataErrReader(strings.NewReader("let x"))
+	r = io.MultiReader(r, iotest.ErrReader(errors.New("connection reset")))
+
+	l := NewFromReader(r)
+
+	expected := []token.TokenType{token.LET, token.IDENT, token.EOF}
+	for i, tt := range expected {
+		tok := l.NextToken()
+
+		if tok.Type != tt {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt, tok.Type)
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Kind != READ_ERROR || errors[0].Error() != "1:6: could not read input: connection reset" {
+		t.Errorf("wrong errors. Got %v", errors)
+	}
+}
+
+func TestTemplateStrings(t *testing.T) {
+	input := `"Hello ${name}, you have ${len(items)} items" "${ {"a": 1}["a"] }" "outer ${ "inner ${x}" }!" "cost: \${x}"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE_HEAD, "Hello "},
+		{token.IDENT, "name"},
+		{token.TEMPLATE_MIDDLE, ", you have "},
+		{token.IDENT, "len"},
+		{token.LPAREN, "("},
+		{token.IDENT, "items"},
+		{token.RPAREN, ")"},
+		{token.TEMPLATE_TAIL, " items"},
+
+		{token.TEMPLATE_HEAD, ""},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "a"},
+		{token.RBRACKET, "]"},
+		{token.TEMPLATE_TAIL, ""},
+
+		{token.TEMPLATE_HEAD, "outer "},
+		{token.TEMPLATE_HEAD, "inner "},
+		{token.IDENT, "x"},
+		{token.TEMPLATE_TAIL, ""},
+		{token.TEMPLATE_TAIL, "!"},
+
+		{token.STRING, "cost: ${x}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminatedTemplateString(t *testing.T) {
+	l := New(`let s = "a ${x`)
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 || errors[0].Kind != UNTERMINATED_STRING || errors[0].Error() != "1:9: unterminated string interpolation" {
+		t.Errorf("wrong errors. Got %v", errors)
+	}
+}
+
+func TestMatchTokens(t *testing.T) {
+	input := `match (x) { 1 => a == b, _ => c = d }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "a"},
+		{token.EQ, "=="},
+		{token.IDENT, "b"},
+		{token.COMMA, ","},
+		{token.IDENT, "_"},
+		{token.ARROW, "=>"},
+		{token.IDENT, "c"},
+		{token.ASSIGN, "="},
+		{token.IDENT, "d"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	input := `f(...rest, 1.5) .. . x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.COMMA, ","},
+		{token.FLOAT, "1.5"},
+		{token.RPAREN, ")"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	expectedErrors := []string{`unexpected characters ".."`, `unexpected character '.'`}
+
+	errors := l.Errors()
+	if len(errors) != len(expectedErrors) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)", len(expectedErrors), len(errors), errors)
+	}
+
+	for i, expected := range expectedErrors {
+		if errors[i].Kind != UNEXPECTED_CHARACTER || errors[i].Message != expected {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%s %q", i, expected, errors[i].Kind, errors[i].Message)
+		}
+	}
+
+	if errors[0].Pos.Offset != 16 || errors[0].End.Offset != 18 {
+		t.Errorf("errors[0] range wrong. expected=16-18, got=%d-%d", errors[0].Pos.Offset, errors[0].End.Offset)
+	}
+}
+
+func TestLexicalErrors(t *testing.T) {
+	input := `let x = 5 @ 3;
+let y = 0xFG + 0b102 + 0o8 + 0x + 1__0 + 2_;
+let z = 1_.5 #$ "ok";`
+
+	expectedTokens := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "5"},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "y"},
+		{token.ASSIGN, "="},
+		{token.INT, "0xFG"},
+		{token.PLUS, "+"},
+		{token.INT, "0b102"},
+		{token.PLUS, "+"},
+		{token.INT, "0o8"},
+		{token.PLUS, "+"},
+		{token.INT, "0x"},
+		{token.PLUS, "+"},
+		{token.INT, "1__0"},
+		{token.PLUS, "+"},
+		{token.INT, "2_"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "z"},
+		{token.ASSIGN, "="},
+		{token.FLOAT, "1_.5"},
+		{token.STRING, "ok"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	expectedErrors := []struct {
+		kind    ErrorKind
+		message string
+		pos     token.Position
+		end     token.Position
+	}{
+		{UNEXPECTED_CHARACTER, `unexpected character '@'`, token.Position{Offset: 10, Line: 1, Column: 11}, token.Position{Offset: 11, Line: 1, Column: 12}},
+		{BAD_NUMBER, `invalid digit 'G' in hexadecimal literal "0xFG"`, token.Position{Offset: 23, Line: 2, Column: 9}, token.Position{Offset: 27, Line: 2, Column: 13}},
+		{BAD_NUMBER, `invalid digit '2' in binary literal "0b102"`, token.Position{Offset: 30, Line: 2, Column: 16}, token.Position{Offset: 35, Line: 2, Column: 21}},
+		{BAD_NUMBER, `invalid digit '8' in octal literal "0o8"`, token.Position{Offset: 38, Line: 2, Column: 24}, token.Position{Offset: 41, Line: 2, Column: 27}},
+		{BAD_NUMBER, `hexadecimal literal "0x" has no digits`, token.Position{Offset: 44, Line: 2, Column: 30}, token.Position{Offset: 46, Line: 2, Column: 32}},
+		{BAD_NUMBER, `'_' must separate successive digits in number literal "1__0"`, token.Position{Offset: 49, Line: 2, Column: 35}, token.Position{Offset: 53, Line: 2, Column: 39}},
+		{BAD_NUMBER, `'_' must separate successive digits in number literal "2_"`, token.Position{Offset: 56, Line: 2, Column: 42}, token.Position{Offset: 58, Line: 2, Column: 44}},
+		{BAD_NUMBER, `'_' must separate successive digits in number literal "1_.5"`, token.Position{Offset: 68, Line: 3, Column: 9}, token.Position{Offset: 72, Line: 3, Column: 13}},
+		{UNEXPECTED_CHARACTER, `unexpected character '#'`, token.Position{Offset: 73, Line: 3, Column: 14}, token.Position{Offset: 74, Line: 3, Column: 15}},
+		{UNEXPECTED_CHARACTER, `unexpected character '$'`, token.Position{Offset: 74, Line: 3, Column: 15}, token.Position{Offset: 75, Line: 3, Column: 16}},
+	}
+
+	l := New(input)
+
+	for i, tt := range expectedTokens {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+
+	errors := l.Errors()
+	if len(errors) != len(expectedErrors) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)", len(expectedErrors), len(errors), errors)
+	}
+
+	for i, tt := range expectedErrors {
+		err := errors[i]
+
+		if err.Kind != tt.kind || err.Message != tt.message {
+			t.Errorf("errors[%d] wrong. expected=%s %q, got=%s %q", i, tt.kind, tt.message, err.Kind, err.Message)
+		}
+
+		if err.Pos != tt.pos || err.End != tt.end {
+			t.Errorf("errors[%d] position wrong. expected=%#v-%#v, got=%#v-%#v", i, tt.pos, tt.end, err.Pos, err.End)
+		}
+	}
+}
+
+func TestEmbeddedNul(t *testing.T) {
+	input := "let a = 1;\x00 let b = 2;"
+
+	expectedTypes := []token.TokenType{
+		token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON,
+		token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON, token.EOF,
+	}
+
+	for _, l := range []*Lexer{New(input), NewFromReader(strings.NewReader(input))} {
+		for i, expected := range expectedTypes {
+			tok := l.NextToken()
+
+			if tok.Type != expected {
+				t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, expected, tok.Type)
+			}
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 || errors[0].Kind != UNEXPECTED_CHARACTER || errors[0].Error() != `1:11: unexpected character '\x00'` {
+			t.Errorf("wrong errors. got=%v", errors)
+		}
+	}
+}
+
+func TestNewAt(t *testing.T) {
+	input := "x\n  + 1"
+	start := token.Position{Offset: 20, Line: 3, Column: 5}
+
+	expected := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.IDENT, token.Position{Offset: 20, Line: 3, Column: 5}, token.Position{Offset: 21, Line: 3, Column: 6}},
+		{token.PLUS, token.Position{Offset: 24, Line: 4, Column: 3}, token.Position{Offset: 25, Line: 4, Column: 4}},
+		{token.INT, token.Position{Offset: 26, Line: 4, Column: 5}, token.Position{Offset: 27, Line: 4, Column: 6}},
+		{token.EOF, token.Position{Offset: 27, Line: 4, Column: 6}, token.Position{Offset: 27, Line: 4, Column: 6}},
+	}
+
+	l := NewAt(input, start)
+
+	for i, tt := range expected {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos != tt.expectedPos || tok.End != tt.expectedEnd {
+			t.Fatalf("tests[%d] - position wrong. expected=%#v-%#v, got=%#v-%#v", i, tt.expectedPos, tt.expectedEnd, tok.Pos, tok.End)
+		}
+	}
+}
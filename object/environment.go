@@ -3,22 +3,38 @@ package object
 func NewEnclosedEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.nullOnFailedMatch = outer.nullOnFailedMatch
 
 	return env
 }
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
+	c := make(map[string]bool)
 
-	return &Environment{store: s, outer: nil}
+	return &Environment{store: s, constants: c, outer: nil}
 }
 
 type Environment struct {
-	store map[string]Object
+	store     map[string]Object
+	constants map[string]bool // names in store bound with const
+	declared  []constant      // the constants, in the order they were bound
+	// A match expression without an arm for its value gives null instead of an error
+	nullOnFailedMatch bool
 	// This let us look from an inner scope to an outer one
 	outer *Environment
 }
 
+// Whether a match expression evaluated in this environment gives null instead of an error
+// when none of its arms matches. Enclosed environments start with the setting of their outer one
+func (e *Environment) NullOnFailedMatch() bool {
+	return e.nullOnFailedMatch
+}
+
+func (e *Environment) SetNullOnFailedMatch(null bool) {
+	e.nullOnFailedMatch = null
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if !ok && e.outer != nil {
@@ -28,8 +44,71 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// Binds name in this environment. A constant can't be bound again in the same environment,
+// in that case we get an *Error back. Inner environments can still shadow it
 func (e *Environment) Set(name string, val Object) Object {
+	if e.constants[name] {
+		return &Error{Message: "cannot redeclare constant: " + name}
+	}
+
 	e.store[name] = val
 
 	return val
 }
+
+type constant struct {
+	name     string
+	previous Object // what name was bound to before, nil if it was unbound
+}
+
+func (e *Environment) SetConst(name string, val Object) Object {
+	previous := e.store[name]
+	result := e.Set(name, val)
+
+	if result.Type() != ERROR_OBJ {
+		e.constants[name] = true
+		e.declared = append(e.declared, constant{name: name, previous: previous})
+	}
+
+	return result
+}
+
+// The number of constants bound in this environment, to pass to DropConstants
+func (e *Environment) ConstantCount() int {
+	return len(e.declared)
+}
+
+// Unbinds the constants bound after the first count ones, giving their names back the values they had before
+func (e *Environment) DropConstants(count int) {
+	for i := len(e.declared) - 1; i >= count; i-- {
+		c := e.declared[i]
+		delete(e.constants, c.name)
+
+		if c.previous != nil {
+			e.store[c.name] = c.previous
+		} else {
+			delete(e.store, c.name)
+		}
+	}
+
+	e.declared = e.declared[:count]
+}
+
+// Rebinds an existing name in the innermost environment that has it, returning false if there's none.
+// Constants can't be rebound: we get an *Error back
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	if _, ok := e.store[name]; ok {
+		if e.constants[name] {
+			return &Error{Message: "cannot assign to constant: " + name}, true
+		}
+
+		e.store[name] = val
+		return val, true
+	}
+
+	if e.outer != nil {
+		return e.outer.Assign(name, val)
+	}
+
+	return nil, false
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"strconv"
 	"strings"
 
 	"github.com/akyrey/monkey-programming-language/ast"
@@ -13,12 +15,15 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	STRING_OBJ       = "STRING"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	BUILTIN_OBJ      = "BUILTIN"
@@ -63,6 +68,29 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// Floating point numbers, stored as IEEE-754 double precision values
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType {
+	return FLOAT_OBJ
+}
+
+// We always print a decimal point or an exponent, so that floats can be told apart from integers
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if strings.ContainsAny(s, ".eIN") {
+		return s
+	}
+
+	return s + ".0"
+}
+
+func (f *Float) HashKey() HashKey {
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 // This is pretty much the same as the integer
 type String struct {
 	Value string
@@ -130,6 +158,25 @@ func (rv *ReturnValue) Inspect() string {
 	return rv.Value.Inspect()
 }
 
+// Like ReturnValue, these stop the evaluation of the blocks they are in, up to the innermost loop
+type Break struct{}
+
+func (b *Break) Type() ObjectType {
+	return BREAK_OBJ
+}
+func (b *Break) Inspect() string {
+	return "break"
+}
+
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType {
+	return CONTINUE_OBJ
+}
+func (c *Continue) Inspect() string {
+	return "continue"
+}
+
 // This is a simple for of errors.
 // In a real world interpreter we'd attach a stack trace, line and column numbers of its origin
 type Error struct {
@@ -147,6 +194,9 @@ func (e *Error) Inspect() string {
 // Parameters and Body are taken directly from the ast definition
 type Function struct {
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression // aligned with Parameters, evaluated at each call that doesn't pass the argument
+	Patterns   []ast.Expression // aligned with Parameters, destructuring the arguments
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -157,13 +207,10 @@ func (f *Function) Type() ObjectType {
 func (f *Function) Inspect() string {
 	var out bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
+	lit := &ast.FunctionLiteral{Parameters: f.Parameters, Defaults: f.Defaults, Patterns: f.Patterns, Rest: f.Rest}
 
 	out.WriteString("fn(")
-	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(lit.ParametersString())
 	out.WriteString(") {\n")
 	out.WriteString(f.Body.String())
 	out.WriteString("\n}")
@@ -230,6 +277,7 @@ func (b *Builtin) Inspect() string {
 //	};
 type Array struct {
 	Elements []Object
+	Frozen   bool // set by the freeze builtin, the elements can't be changed anymore
 }
 
 func (a *Array) Type() ObjectType {
@@ -258,7 +306,8 @@ type HashPair struct {
 // We use HashPair to be able to print the keys in our REPL, otherwise we'd have only the hashed key
 // Would also be useful if we implemented a range function to iterate over keys and values
 type Hash struct {
-	Pairs map[HashKey]HashPair
+	Pairs  map[HashKey]HashPair
+	Frozen bool // set by the freeze builtin, the pairs can't be changed anymore
 }
 
 func (h *Hash) Type() ObjectType {
@@ -58,3 +58,39 @@ func TestIntegerHashKey(t *testing.T) {
 		t.Errorf("integers with different content have same hash keys")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	one1 := &Float{Value: 1.5}
+	one2 := &Float{Value: 1.5}
+	diff := &Float{Value: 2.5}
+
+	if one1.HashKey() != one2.HashKey() {
+		t.Errorf("floats with same content have different hash keys")
+	}
+
+	if one1.HashKey() == diff.HashKey() {
+		t.Errorf("floats with different content have same hash keys")
+	}
+
+	if (&Float{Value: 1}).HashKey() == (&Integer{Value: 1}).HashKey() {
+		t.Errorf("float and integer with same value have same hash keys")
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1.5, "1.5"},
+		{2, "2.0"},
+		{-3, "-3.0"},
+		{1e21, "1e+21"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("wrong Inspect for %g. Got %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+}
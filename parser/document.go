@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/akyrey/monkey-programming-language/ast"
+	"github.com/akyrey/monkey-programming-language/lexer"
+	"github.com/akyrey/monkey-programming-language/token"
+)
+
+// A text change, as sent by editors: the bytes between Start and End (excluded) are replaced by Text.
+// Offsets refer to the text before the change
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// A Document keeps the source of a program together with its AST, so that after an edit only the
+// top level statements around the change need to be lexed and parsed again.
+// The statements that are reused keep their identity: their positions are updated in place
+type Document struct {
+	text   string
+	chunks []chunk
+}
+
+// The part of the text parsed by a single parseStatement call at the top level of the program.
+// Chunks follow each other: a chunk starts with the comments before its first token and ends where the next one starts
+type chunk struct {
+	start    token.Position
+	end      int
+	stmt     ast.Statement
+	errors   ErrorList
+	reusable bool // no errors were found in the run that parsed it, up to its end
+}
+
+func NewDocument(input string) *Document {
+	d := &Document{text: input}
+	d.chunks, _ = d.parse(token.Position{Offset: 0, Line: 1, Column: 1}, nil)
+
+	return d
+}
+
+func (d *Document) Text() string {
+	return d.text
+}
+
+// Builds a new program every time, but the statements in it are shared with the programs
+// returned before the last edits, when they could be reused
+func (d *Document) Program() *ast.Program {
+	program := &ast.Program{Statements: []ast.Statement{}}
+
+	for _, c := range d.chunks {
+		if c.stmt != nil {
+			program.Statements = append(program.Statements, c.stmt)
+		}
+	}
+
+	return program
+}
+
+// The same errors a Parser would report for the whole text
+func (d *Document) Errors() []string {
+	return ErrorList(d.ParseErrors()).Strings()
+}
+
+func (d *Document) ParseErrors() []*ParseError {
+	errors := []*ParseError{}
+
+	for _, c := range d.chunks {
+		errors = append(errors, c.errors...)
+	}
+
+	return errors
+}
+
+// Applies the edit to the text and updates the program.
+// We start parsing again from the statement before the one containing the edit, since the edit could
+// make it longer (think of a new line starting with an operator), and we go on until we get back
+// in sync with a statement that starts after the edit: from there on the old chunks are reused
+func (d *Document) Apply(edit Edit) error {
+	if edit.Start < 0 || edit.Start > edit.End || edit.End > len(d.text) {
+		return fmt.Errorf("invalid edit range %d-%d for a document of %d bytes", edit.Start, edit.End, len(d.text))
+	}
+
+	// Index of the first chunk that could have changed
+	first := 0
+	for first < len(d.chunks) && d.chunks[first].end <= edit.Start {
+		first += 1
+	}
+	if first > 0 {
+		first -= 1
+	}
+	// Chunks found after an error can depend on how the parser recovered from it
+	for i := 0; i < first; i++ {
+		if !d.chunks[i].reusable {
+			first = i
+			break
+		}
+	}
+
+	start := token.Position{Offset: 0, Line: 1, Column: 1}
+	if first > 0 {
+		start = d.chunks[first].start
+	}
+
+	oldEnd := advance(start, d.text[start.Offset:edit.End])
+	d.text = d.text[:edit.Start] + edit.Text + d.text[edit.End:]
+	newEnd := advance(start, d.text[start.Offset:edit.Start+len(edit.Text)])
+
+	// Moves a position that comes after the edit to where it is in the new text
+	move := func(pos *token.Position) {
+		if pos.Line == oldEnd.Line {
+			pos.Column += newEnd.Column - oldEnd.Column
+		}
+		pos.Line += newEnd.Line - oldEnd.Line
+		pos.Offset += newEnd.Offset - oldEnd.Offset
+	}
+
+	// The old chunks we can get back in sync with, by their offset in the new text
+	old := d.chunks[first:]
+	after := map[int]int{}
+	for i, c := range old {
+		if c.start.Offset >= edit.End && c.reusable {
+			after[c.start.Offset+newEnd.Offset-oldEnd.Offset] = i
+		}
+	}
+
+	chunks := append([]chunk{}, d.chunks[:first]...)
+	parsed, i := d.parse(start, after)
+	chunks = append(chunks, parsed...)
+
+	if i >= 0 {
+		for ; i < len(old) && old[i].reusable; i++ {
+			c := old[i]
+			move(&c.start)
+			c.end += newEnd.Offset - oldEnd.Offset
+			shiftPositions(reflect.ValueOf(c.stmt), move, map[uintptr]bool{})
+			chunks = append(chunks, c)
+		}
+
+		// Once there's an error, nothing after it is reusable, so we parse again till the end
+		if i < len(old) {
+			start = old[i].start
+			move(&start)
+			parsed, _ = d.parse(start, nil)
+			chunks = append(chunks, parsed...)
+		}
+	}
+
+	d.chunks = chunks
+
+	return nil
+}
+
+// Parses the text from start, a position at the beginning of a statement, to its end.
+// The parsing stops before a statement starting at one of the offsets in after, provided no error was found so far,
+// since from there on the caller can reuse the chunks it already has: in that case we also return the value
+// associated to the offset, otherwise -1
+func (d *Document) parse(start token.Position, after map[int]int) ([]chunk, int) {
+	p := New(lexer.NewAt(d.text[start.Offset:], start))
+	chunks := []chunk{}
+
+	for !p.curTokenIs(token.EOF) {
+		pos := chunkStart(p.curToken)
+
+		if i, ok := after[pos.Offset]; ok && len(p.errors) == 0 && len(chunks) > 0 {
+			return chunks, i
+		}
+
+		errors := len(p.errors)
+		if len(chunks) == 0 {
+			errors = 0
+		}
+
+		stmt := p.parseStatementRecovering(0)
+		p.nextToken()
+
+		c := chunk{start: pos, end: chunkStart(p.curToken).Offset, stmt: stmt, reusable: len(p.errors) == 0}
+		if len(p.errors) > errors {
+			c.errors = p.errors[errors:len(p.errors):len(p.errors)]
+		}
+
+		chunks = append(chunks, c)
+	}
+
+	// Without statements, we still need a chunk to keep the errors found lexing comments and whitespace
+	if len(chunks) == 0 {
+		if len(p.errors) > 0 {
+			chunks = append(chunks, chunk{start: start, end: len(d.text), errors: p.errors})
+		}
+		return chunks, -1
+	}
+
+	// The comments at the end of the text belong to the last chunk
+	chunks[len(chunks)-1].end = len(d.text)
+
+	return chunks, -1
+}
+
+// A chunk starts with the comments before its first token
+func chunkStart(tok token.Token) token.Position {
+	if len(tok.Trivia) > 0 {
+		return tok.Trivia[0].Pos
+	}
+
+	return tok.Pos
+}
+
+// Returns the position reached moving from pos across text, counting lines and columns like the lexer does
+func advance(pos token.Position, text string) token.Position {
+	for _, ch := range text {
+		if ch == '\n' {
+			pos.Line += 1
+			pos.Column = 1
+		} else {
+			pos.Column += 1
+		}
+	}
+	pos.Offset += len(text)
+
+	return pos
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Walks a node looking for the tokens in it, and moves their positions and the ones of their trivia.
+// Going through reflection means new node types don't need to be taught here.
+// Tokens are copied around between nodes, but their trivia slices are shared, so we keep track of what we moved
+func shiftPositions(v reflect.Value, move func(*token.Position), seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		shiftPositions(v.Elem(), move, seen)
+	case reflect.Interface:
+		if !v.IsNil() {
+			shiftPositions(v.Elem(), move, seen)
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType && v.CanAddr() {
+			tok := v.Addr().Interface().(*token.Token)
+			move(&tok.Pos)
+			move(&tok.End)
+
+			if len(tok.Trivia) > 0 && !seen[reflect.ValueOf(tok.Trivia).Pointer()] {
+				seen[reflect.ValueOf(tok.Trivia).Pointer()] = true
+				for i := range tok.Trivia {
+					move(&tok.Trivia[i].Pos)
+					move(&tok.Trivia[i].End)
+				}
+			}
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			shiftPositions(v.Field(i), move, seen)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			shiftPositions(v.Index(i), move, seen)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			shiftPositions(iter.Key(), move, seen)
+			shiftPositions(iter.Value(), move, seen)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package parser
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/akyrey/monkey-programming-language/ast"
+	"github.com/akyrey/monkey-programming-language/lexer"
+	"github.com/akyrey/monkey-programming-language/token"
+)
+
+const documentInput = `// Adds two numbers
+let add = fn(a, b) { a + b };
+let five = 5;
+
+/* a hash */
+let h = {"one": 1, "two": add(1, 1)};
+let greeting = "hello ${h["one"]} world";
+
+if (five > 3) {
+  add(five, 10)
+} else {
+  five
+}
+[1, 2, 3][1];
+`
+
+func TestDocumentMatchesParseProgram(t *testing.T) {
+	edits := []Edit{
+		// Change a value in the middle of a statement
+		{Start: strings.Index(documentInput, "5;"), End: strings.Index(documentInput, "5;") + 1, Text: "50"},
+		// Add a new line at the beginning
+		{Start: 0, End: 0, Text: "let zero = 0;\n"},
+		// Break a statement, then fix it
+		{Start: 19, End: 20, Text: ""},
+		{Start: 19, End: 19, Text: "0"},
+		// Continue an expression on the next statement
+		{Start: len("let zero = 0;\n// Adds two numbers\nlet add = fn(a, b) { a + b };\n"), End: len("let zero = 0;\n// Adds two numbers\nlet add = fn(a, b) { a + b };\nlet"), Text: "+ 1;\nlet"},
+		// Open a string that is never closed, then close it
+		{Start: 0, End: 0, Text: `"`},
+		{Start: 1, End: 1, Text: `";`},
+		// Add lines at the end
+		{Start: -1, End: -1, Text: "let last = fn(x) {\n  x * 2\n};\nlast(3)"},
+		// Remove everything
+		{Start: 0, End: -1, Text: ""},
+		{Start: 0, End: 0, Text: "  // just a comment"},
+	}
+
+	doc := NewDocument(documentInput)
+	checkDocument(t, doc)
+
+	for i, edit := range edits {
+		if edit.Start < 0 {
+			edit.Start = len(doc.Text())
+		}
+		if edit.End < 0 {
+			edit.End = len(doc.Text())
+		}
+
+		if err := doc.Apply(edit); err != nil {
+			t.Fatalf("edits[%d] - unexpected error: %s", i, err)
+		}
+
+		checkDocument(t, doc)
+	}
+}
+
+func TestDocumentReusesStatements(t *testing.T) {
+	input := "let a = 1;\nlet b = 2;\nlet c = 3;\nlet d = 4;\nlet e = 5;\n"
+
+	doc := NewDocument(input)
+	before := doc.Program().Statements
+
+	// Changes the value of c
+	offset := strings.Index(input, "3")
+	if err := doc.Apply(Edit{Start: offset, End: offset + 1, Text: "\n  33"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	after := doc.Program().Statements
+	checkDocument(t, doc)
+
+	if len(after) != len(before) {
+		t.Fatalf("wrong number of statements. Want %d. Got %d", len(before), len(after))
+	}
+
+	// b is parsed again with c, since an edit can extend the statement before it
+	reused := []bool{true, false, false, true, true}
+
+	for i, reuse := range reused {
+		if (before[i] == after[i]) != reuse {
+			t.Errorf("statement %d (%s) reused=%t, want %t", i, after[i], before[i] == after[i], reuse)
+		}
+	}
+
+	last := after[4].(*ast.LetStatement)
+	expected := token.Position{Offset: 48, Line: 6, Column: 1}
+	if last.Token.Pos != expected {
+		t.Errorf("reused statement not moved. Want %#v. Got %#v", expected, last.Token.Pos)
+	}
+}
+
+func TestDocumentRandomEdits(t *testing.T) {
+	snippets := []string{"let", " ", "\n", "x", "1", ";", "+", "(", ")", "{", "}", "fn", "\"", "${", "[", "]", ",", ":", "// c\n", "/*", "*/", "if", "else", "é", "``"}
+	random := rand.New(rand.NewSource(1))
+
+	doc := NewDocument(documentInput)
+
+	for i := 0; i < 500; i++ {
+		text := doc.Text()
+		start := random.Intn(len(text) + 1)
+		end := start
+		if random.Intn(2) == 0 {
+			end += random.Intn(10)
+			if end > len(text) {
+				end = len(text)
+			}
+		}
+
+		// Keep the edits on char boundaries
+		for start > 0 && start < len(text) && !isCharStart(text, start) {
+			start -= 1
+		}
+		for end < len(text) && !isCharStart(text, end) {
+			end += 1
+		}
+
+		insert := ""
+		for n := random.Intn(4); n > 0; n-- {
+			insert += snippets[random.Intn(len(snippets))]
+		}
+
+		if err := doc.Apply(Edit{Start: start, End: end, Text: insert}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !checkDocument(t, doc) {
+			t.Fatalf("edit %d replacing %d-%d with %q went wrong", i, start, end, insert)
+		}
+	}
+}
+
+func TestDocumentInvalidEdits(t *testing.T) {
+	tests := []struct {
+		edit     Edit
+		expected string
+	}{
+		{Edit{Start: -1, End: 2}, "invalid edit range -1-2 for a document of 10 bytes"},
+		{Edit{Start: 3, End: 2}, "invalid edit range 3-2 for a document of 10 bytes"},
+		{Edit{Start: 3, End: 11}, "invalid edit range 3-11 for a document of 10 bytes"},
+	}
+
+	for _, tt := range tests {
+		doc := NewDocument("let x = 1;")
+
+		err := doc.Apply(tt.edit)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error. Want %q. Got %v", tt.expected, err)
+		}
+
+		if doc.Text() != "let x = 1;" {
+			t.Errorf("text changed after an invalid edit. Got %q", doc.Text())
+		}
+	}
+}
+
+func isCharStart(text string, offset int) bool {
+	return text[offset]&0xC0 != 0x80
+}
+
+// Compares the document with what we get parsing its whole text, positions included
+func checkDocument(t *testing.T, doc *Document) bool {
+	t.Helper()
+
+	p := New(lexer.New(doc.Text()))
+	expected := p.ParseProgram()
+	program := doc.Program()
+
+	ok := true
+
+	if dumpNode(reflect.ValueOf(program)) != dumpNode(reflect.ValueOf(expected)) {
+		t.Errorf("program wrong for %q.\nWant %s\nGot  %s", doc.Text(), dumpNode(reflect.ValueOf(expected)), dumpNode(reflect.ValueOf(program)))
+		ok = false
+	}
+
+	if strings.Join(doc.Errors(), "\n") != strings.Join(p.Errors(), "\n") {
+		t.Errorf("errors wrong for %q.\nWant %q\nGot  %q", doc.Text(), p.Errors(), doc.Errors())
+		ok = false
+	}
+
+	return ok
+}
+
+// Describes a node with all of its fields, tokens and positions included.
+// We can't use String, that doesn't handle the nil expressions left by parse errors,
+// and we can't use reflect.DeepEqual either, since hash literals have pointers as keys
+func dumpNode(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return dumpNode(v.Elem())
+	case reflect.Struct:
+		fields := []string{}
+		for i := 0; i < v.NumField(); i++ {
+			fields = append(fields, v.Type().Field(i).Name+":"+dumpNode(v.Field(i)))
+		}
+		return v.Type().Name() + "{" + strings.Join(fields, " ") + "}"
+	case reflect.Slice:
+		elements := []string{}
+		for i := 0; i < v.Len(); i++ {
+			elements = append(elements, dumpNode(v.Index(i)))
+		}
+		return "[" + strings.Join(elements, " ") + "]"
+	case reflect.Map:
+		pairs := []string{}
+		iter := v.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, dumpNode(iter.Key())+"=>"+dumpNode(iter.Value()))
+		}
+		sort.Strings(pairs)
+		return "map[" + strings.Join(pairs, " ") + "]"
+	default:
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+}
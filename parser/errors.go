@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/akyrey/monkey-programming-language/ast"
+	"github.com/akyrey/monkey-programming-language/lexer"
+	"github.com/akyrey/monkey-programming-language/token"
+)
+
+type ErrorKind string
+
+const (
+	UNEXPECTED_TOKEN    = "UNEXPECTED_TOKEN"    // the next token isn't one of the expected ones
+	MISSING_EXPRESSION  = "MISSING_EXPRESSION"  // there's no prefix parse function for the token where an expression should start
+	NUMBER_OUT_OF_RANGE = "NUMBER_OUT_OF_RANGE" // the number literal doesn't fit in 64 bits
+	INVALID_ASSIGNMENT  = "INVALID_ASSIGNMENT"  // the left side of = is neither a variable nor an index expression
+	INVALID_PARAMETER   = "INVALID_PARAMETER"   // what's before the => of an arrow function can't be a parameter list
+)
+
+// A diagnostic found while parsing.
+// Errors reported by the lexer are turned into a ParseError too, keeping the lexer.ErrorKind as Kind
+type ParseError struct {
+	Kind     ErrorKind
+	Message  string
+	Pos      token.Position // where the offending token starts
+	End      token.Position // right after the offending token
+	Expected []token.TokenType
+	Found    token.TokenType // type of the offending token, empty for lexical errors
+}
+
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
+// All the errors found parsing a program, in the order they were found
+type ErrorList []*ParseError
+
+func (list ErrorList) Error() string {
+	switch len(list) {
+	case 0:
+		return "no errors"
+	case 1:
+		return list[0].Error()
+	default:
+		return fmt.Sprintf("%s (and %d more errors)", list[0], len(list)-1)
+	}
+}
+
+// Returns the errors in the same form Parser.Errors uses
+func (list ErrorList) Strings() []string {
+	errors := make([]string, len(list))
+
+	for i, err := range list {
+		errors[i] = err.Error()
+	}
+
+	return errors
+}
+
+// Parses input in one go. When something goes wrong the error is an ErrorList and the program contains
+// the statements the parser managed to recover
+func Parse(input string) (*ast.Program, error) {
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+
+	if len(p.errors) > 0 {
+		return program, p.errors
+	}
+
+	return program, nil
+}
+
+// Reports an error on tok, unless we are still recovering from a previous one: the parser is out of sync
+// with the input at that point, so what it finds is most likely bogus
+func (p *Parser) error(kind ErrorKind, tok token.Token, expected []token.TokenType, format string, a ...interface{}) {
+	if p.panicking {
+		return
+	}
+
+	p.errors = append(p.errors, &ParseError{
+		Kind:     kind,
+		Message:  fmt.Sprintf(format, a...),
+		Pos:      tok.Pos,
+		End:      tok.End,
+		Expected: expected,
+		Found:    tok.Type,
+	})
+	p.panicking = true
+}
+
+func fromLexerError(err *lexer.Error) *ParseError {
+	return &ParseError{Kind: ErrorKind(err.Kind), Message: err.Message, Pos: err.Pos, End: err.End}
+}
@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
 	"strconv"
 
 	"github.com/akyrey/monkey-programming-language/ast"
@@ -14,27 +14,48 @@ import (
 const (
 	_ int = iota // this gives the following constants incrementing numbers as values, starting with 0 here
 	LOWEST
+	ASSIGNMENT     // x = y
+	PIPELINE       // x |> f(y)
+	LOGICAL_OR     // ||
+	LOGICAL_AND    // &&
+	BIT_OR         // |
+	BIT_XOR        // ^
+	BIT_AND        // &
 	EQUALS         // ==
-	LESSER_GREATER // > or <
+	LESSER_GREATER // >, <, >= or <=
+	SHIFT          // << or >>
 	SUM            // +
-	PRODUCT        // *
-	PREFIX         // -X or !X
+	PRODUCT        // *, / or %
+	PREFIX         // -X, !X or ~X
 	CALL           // myFunction(X)
 	INDEX          // array[index]
 )
 
 // Precedence table - associates token types with their precedence
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSER_GREATER,
-	token.GT:       LESSER_GREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:      ASSIGNMENT,
+	token.ARROW:       ASSIGNMENT,
+	token.PIPELINE:    PIPELINE,
+	token.OR:          LOGICAL_OR,
+	token.AND:         LOGICAL_AND,
+	token.PIPE:        BIT_OR,
+	token.CARET:       BIT_XOR,
+	token.AMPERSAND:   BIT_AND,
+	token.EQ:          EQUALS,
+	token.NOT_EQ:      EQUALS,
+	token.LT:          LESSER_GREATER,
+	token.GT:          LESSER_GREATER,
+	token.LT_EQ:       LESSER_GREATER,
+	token.GT_EQ:       LESSER_GREATER,
+	token.SHIFT_LEFT:  SHIFT,
+	token.SHIFT_RIGHT: SHIFT,
+	token.PLUS:        SUM,
+	token.MINUS:       SUM,
+	token.SLASH:       PRODUCT,
+	token.ASTERISK:    PRODUCT,
+	token.PERCENT:     PRODUCT,
+	token.LPAREN:      CALL,
+	token.LBRACKET:    INDEX,
 }
 
 // We need to look at the curToken, which is the current token under
@@ -44,15 +65,21 @@ type Parser struct {
 	l              *lexer.Lexer
 	curToken       token.Token
 	peekToken      token.Token
-	errors         []string
+	errors         ErrorList
+	lexerErrors    int  // number of lexer errors already moved into errors
+	panicking      bool // we found an error and the following ones are just its consequences, until we synchronize
+	depth          int  // number of braces opened before curToken and not closed yet
+	nesting        int  // number of parentheses, brackets and braces opened up to curToken and not closed yet
+	guardNesting   int  // while parsing a match guard, the nesting around it, otherwise -1
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
-		l:      l,
-		errors: []string{},
+		l:            l,
+		errors:       ErrorList{},
+		guardNesting: -1,
 	}
 
 	// Read two tokens, so curToken and peekToken are both set
@@ -62,9 +89,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE_HEAD, p.parseTemplateLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupExpression)
@@ -73,6 +103,8 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -83,6 +115,19 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_RIGHT, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PIPELINE, p.parsePipelineExpression)
+	p.registerInfix(token.ARROW, p.parseArrowExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
@@ -96,7 +141,7 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseStatementRecovering(0)
 
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
@@ -108,27 +153,108 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// The errors in their string form, "line:column: message"
 func (p *Parser) Errors() []string {
+	return p.errors.Strings()
+}
+
+func (p *Parser) ParseErrors() []*ParseError {
 	return p.errors
 }
 
+// Parses a statement and, if it turns out to be broken, skips what's left of it.
+// level is the number of braces open around the list of statements we are in
+func (p *Parser) parseStatementRecovering(level int) ast.Statement {
+	stmt := p.parseStatement()
+
+	if p.panicking {
+		p.synchronize(level)
+	}
+
+	return stmt
+}
+
+// The parse functions give us a nil pointer for a statement they couldn't parse. We turn it into a nil interface,
+// otherwise the broken statement would look like a valid one to whoever checks stmt != nil
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		if stmt := p.parseLetStatement(); stmt != nil {
+			return stmt
+		}
+	case token.CONST:
+		if stmt := p.parseConstStatement(); stmt != nil {
+			return stmt
+		}
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		if stmt := p.parseWhileStatement(); stmt != nil {
+			return stmt
+		}
+	case token.FOR:
+		if stmt := p.parseForStatement(); stmt != nil {
+			return stmt
+		}
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.FUNCTION:
+		// Without a name it's a function literal used as an expression
+		if !p.peekTokenIs(token.IDENT) {
+			return p.parseExpressionStatement()
+		}
+		if stmt := p.parseFunctionStatement(); stmt != nil {
+			return stmt
+		}
 	default:
 		return p.parseExpressionStatement()
 	}
+
+	return nil
 }
 
 // Constructs an *ast.LetStatement node with the token it's currently sitting on
 // and advances the tokens while making assertions about the next token with calls to [expectPeek]
-// A let statement must have an equal sign and an expression, ending with a semicolon
+// A let statement must have an equal sign and an expression, ending with a semicolon.
+// In place of the name there can be a pattern destructuring the value
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
 
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+
+		if stmt.Pattern = p.parsePattern(false); stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Value = p.parseExpression(LOWEST)
+
+	for p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// Same syntax as a let statement: const name = value;
+func (p *Parser) parseConstStatement() *ast.ConstStatement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -150,6 +276,29 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 	return stmt
 }
 
+// fn name(parameters) { body }
+// The function is parsed like a literal, starting from the name instead of the fn token
+func (p *Parser) parseFunctionStatement() *ast.FunctionStatement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	function, ok := p.parseFunctionLiteral().(*ast.FunctionLiteral)
+	if !ok {
+		return nil
+	}
+
+	function.Token = stmt.Token
+	stmt.Function = function
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
@@ -164,6 +313,92 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+// while (condition) { body }
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// for (variable in iterable) { body }
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
@@ -207,11 +442,10 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 // Helper method to print info about missing prefix parse functions
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.error(MISSING_EXPRESSION, p.curToken, nil, "no prefix parse function for %s found", t)
 }
 
-// This is called while curToken is ! or -, so we need to advance and consume next token too
+// This is called while curToken is !, - or ~, so we need to advance and consume next token too
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -241,6 +475,46 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// Assignment is right associative: a = b = c is a = (b = c), so we parse the right side with a lower precedence.
+// Only variables and indexes can be assigned
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{Token: p.curToken, Target: target}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case nil:
+		return nil // the error was already reported
+	default:
+		p.error(INVALID_ASSIGNMENT, p.curToken, nil, "cannot assign to %s", target)
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGNMENT - 1)
+
+	return expression
+}
+
+// x |> f(y) is just another way to write f(x, y), so we don't need a node for it: the call we build
+// works the same with builtins, functions and macros. Without parentheses, x |> f is f(x).
+// It's left associative, x |> f |> g is g(f(x))
+func (p *Parser) parsePipelineExpression(left ast.Expression) ast.Expression {
+	pipe := p.curToken
+
+	p.nextToken()
+	right := p.parseExpression(PIPELINE)
+
+	switch right := right.(type) {
+	case nil:
+		return nil
+	case *ast.CallExpression:
+		right.Arguments = append([]ast.Expression{left}, right.Arguments...)
+		return right
+	default:
+		return &ast.CallExpression{Token: pipe, Function: right, Arguments: []ast.Expression{left}}
+	}
+}
+
 // What's important here is that this doesn't call nextToken. All our parsing functions, prefixParseFns and infixParseFns won't
 // call nextToken, because we want to start with curToken being the type of token you're associated with and return with curToken
 // being the last token that's part of out expression type
@@ -248,14 +522,43 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// Base 0 lets strconv handle the 0x, 0o and 0b prefixes and the underscores between digits
+// Malformed literals are already reported by the lexer, so we only need to check the range here.
+// Base 0 would also read 010 as octal: the lexer rejects it, so we don't give it a value
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
+	if lexer.HasLeadingZero(p.curToken.Literal) {
+		return nil
+	}
+
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 
+	if errors.Is(err, strconv.ErrRange) {
+		p.error(NUMBER_OUT_OF_RANGE, p.curToken, nil, "integer literal %q out of range", p.curToken.Literal)
+		return nil
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+
+	if errors.Is(err, strconv.ErrRange) {
+		p.error(NUMBER_OUT_OF_RANGE, p.curToken, nil, "float literal %q out of range", p.curToken.Literal)
+		return nil
+	}
+
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
 		return nil
 	}
 
@@ -268,20 +571,155 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// The lexer gives us the text parts of the string as TEMPLATE_* tokens, with the tokens of the
+// interpolated expressions in between. Empty text parts are left out of the AST. When an interpolated
+// expression fails to parse we still read up to the end of the string, but return no literal
+func (p *Parser) parseTemplateLiteral() ast.Expression {
+	template := &ast.TemplateLiteral{Token: p.curToken}
+	template.Parts = p.appendTemplateText(template.Parts)
+	failed := false
+
+	for {
+		p.nextToken() // We move to the expression from the text before it
+
+		if p.curTokenIs(token.TEMPLATE_MIDDLE) || p.curTokenIs(token.TEMPLATE_TAIL) {
+			p.error(MISSING_EXPRESSION, p.curToken, nil, "empty interpolation in string")
+			failed = true
+		} else {
+			if part := p.parseExpression(LOWEST); part != nil {
+				template.Parts = append(template.Parts, part)
+			} else {
+				failed = true
+			}
+
+			// The } closing the interpolation comes with the text after it
+			if !p.peekTokenIs(token.TEMPLATE_MIDDLE) && !p.peekTokenIs(token.TEMPLATE_TAIL) {
+				p.error(UNEXPECTED_TOKEN, p.peekToken, []token.TokenType{token.RBRACE}, "expected } after interpolated expression, got %s instead", p.peekToken.Type)
+				return nil
+			}
+
+			p.nextToken()
+		}
+
+		template.Parts = p.appendTemplateText(template.Parts)
+
+		if p.curTokenIs(token.TEMPLATE_TAIL) {
+			if failed {
+				return nil
+			}
+
+			return template
+		}
+	}
+}
+
+func (p *Parser) appendTemplateText(parts []ast.Expression) []ast.Expression {
+	if p.curToken.Literal == "" {
+		return parts
+	}
+
+	return append(parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.curToken, Value: p.curTokenIs(token.TRUE)}
 }
 
+// A parenthesized expression, or the parameters of an arrow function: () => 1, (a, b = 1, ...rest) => a + b.
+// We can't tell which one it is until we find a => after the parentheses, so we parse a list of expressions
+// and turn them into parameters when needed
 func (p *Parser) parseGroupExpression() ast.Expression {
-	p.nextToken()
+	start := p.curToken
+	exps := []ast.Expression{}
 
-	exp := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		exps = append(exps, p.parseExpression(LOWEST))
+
+		for p.peekTokenIs(token.COMMA) {
+			p.nextToken()
+			p.nextToken()
+			exps = append(exps, p.parseExpression(LOWEST))
+		}
+	}
 
 	if !p.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return exp
+	// In a match guard, a => right after the parentheses is the end of the guard
+	if p.peekTokenIs(token.ARROW) && p.nesting != p.guardNesting {
+		p.nextToken()
+		return p.parseArrowFunction(start, exps)
+	}
+
+	if len(exps) != 1 {
+		p.peekError(token.ARROW)
+		return nil
+	}
+
+	return exps[0]
+}
+
+// x => x * 2, an arrow function with a single parameter doesn't need parentheses
+func (p *Parser) parseArrowExpression(left ast.Expression) ast.Expression {
+	ident, ok := left.(*ast.Identifier)
+	if !ok {
+		if left != nil {
+			p.error(INVALID_PARAMETER, p.curToken, nil, "invalid parameter in arrow function: %s", left)
+		}
+		return nil
+	}
+
+	return p.parseArrowFunction(ident.Token, []ast.Expression{ident})
+}
+
+// Builds the function literal for an arrow function, with curToken on the =>.
+// The parameters are parsed as expressions: a = 1 is a parameter with a default value and ...rest collects
+// the remaining arguments. The body is a single expression.
+// We get the same literal fn(params) { body } would give us, so nothing else needs to know about arrow functions
+func (p *Parser) parseArrowFunction(start token.Token, params []ast.Expression) ast.Expression {
+	lit := &ast.FunctionLiteral{
+		Token:      token.Token{Type: token.FUNCTION, Literal: "fn", Pos: start.Pos, End: start.End, Trivia: start.Trivia},
+		Parameters: []*ast.Identifier{},
+		Defaults:   []ast.Expression{},
+		Patterns:   []ast.Expression{},
+	}
+
+	for i, param := range params {
+		var name *ast.Identifier
+		var def ast.Expression
+
+		switch param := param.(type) {
+		case *ast.Identifier:
+			name = param
+		case *ast.AssignExpression:
+			name, _ = param.Target.(*ast.Identifier)
+			def = param.Value
+		case *ast.SpreadExpression:
+			if ident, ok := param.Value.(*ast.Identifier); ok && i == len(params)-1 {
+				lit.Rest = ident
+				continue
+			}
+		}
+
+		if name == nil {
+			p.error(INVALID_PARAMETER, p.curToken, nil, "invalid parameter in arrow function: %s", param)
+			return nil
+		}
+
+		lit.Parameters = append(lit.Parameters, name)
+		lit.Defaults = append(lit.Defaults, def)
+		lit.Patterns = append(lit.Patterns, nil)
+	}
+
+	arrow := p.curToken
+	p.nextToken()
+
+	stmt := &ast.ExpressionStatement{Token: p.curToken, Expression: p.parseExpression(LOWEST)}
+	lit.Body = &ast.BlockStatement{Token: arrow, Statements: []ast.Statement{stmt}}
+
+	return lit
 }
 
 func (p *Parser) parseArrayLiteral() ast.Expression {
@@ -340,6 +778,23 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
+		// else if (...) {}: the alternative is a block with the nested if expression as its only statement,
+		// the same we get writing else { if (...) {} }
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+
+			block := &ast.BlockStatement{Token: p.curToken}
+			nested := p.parseIfExpression()
+			if nested == nil {
+				return nil
+			}
+
+			block.Statements = []ast.Statement{&ast.ExpressionStatement{Token: block.Token, Expression: nested}}
+			expression.Alternative = block
+
+			return expression
+		}
+
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
@@ -350,19 +805,87 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+// match (subject) { pattern => result, pattern if guard => result }
+// Arms are separated by commas, and the last one can be followed by one too
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.curToken, Arms: []*ast.MatchArm{}}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := &ast.MatchArm{Pattern: p.parsePattern(true)}
+		if arm.Pattern == nil {
+			return nil
+		}
+
+		// The guard ends before the =>, which would otherwise start an arrow function
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+
+			outer := p.guardNesting
+			p.guardNesting = p.nesting
+
+			p.nextToken()
+			arm.Guard = p.parseExpression(ASSIGNMENT)
+
+			p.guardNesting = outer
+		}
+
+		if !p.expectPeek(token.ARROW) {
+			return nil
+		}
+
+		p.nextToken()
+		arm.Result = p.parseExpression(LOWEST)
+
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.curToken}
 	block.Statements = []ast.Statement{}
+	level := p.depth
 
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseStatementRecovering(level)
 
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
 
+		// The broken statement went on up to the brace closing the block
+		if p.depth < level {
+			break
+		}
+
 		p.nextToken()
 	}
 
@@ -376,7 +899,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	lit.Parameters = p.parseFunctionParameter()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -387,6 +912,170 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return lit
 }
 
+// Each parameter can be a pattern destructuring its argument and can have a default value,
+// while the last one can collect the remaining arguments: fn(a, [b, c], d = 2, ...rest)
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
+	lit.Defaults = []ast.Expression{}
+	lit.Patterns = []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return true
+	}
+
+	for {
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+
+			// Nothing can follow the rest parameter
+			lit.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		var pattern ast.Expression
+		if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+			p.nextToken()
+
+			if pattern = p.parsePattern(false); pattern == nil {
+				return false
+			}
+
+			// A destructured parameter has no name of its own
+			lit.Parameters = append(lit.Parameters, nil)
+		} else {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+
+			lit.Parameters = append(lit.Parameters, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+		}
+		lit.Patterns = append(lit.Patterns, pattern)
+
+		var def ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			def = p.parseExpression(LOWEST)
+		}
+		lit.Defaults = append(lit.Defaults, def)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	return p.expectPeek(token.RPAREN)
+}
+
+// Parses the pattern starting at the current token: an identifier, or an array or hash pattern
+// that can contain other patterns.
+// Refutable patterns, the ones of match expressions, can also be literals that the value must be equal to
+func (p *Parser) parsePattern(refutable bool) ast.Expression {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET:
+		return p.parseArrayPattern(refutable)
+	case token.LBRACE:
+		return p.parseHashPattern(refutable)
+	case token.INT, token.FLOAT, token.STRING, token.TRUE, token.FALSE:
+		if refutable {
+			return p.prefixParseFns[p.curToken.Type]()
+		}
+	case token.MINUS:
+		if refutable && (p.peekTokenIs(token.INT) || p.peekTokenIs(token.FLOAT)) {
+			return p.parsePrefixExpression()
+		}
+	}
+
+	p.error(UNEXPECTED_TOKEN, p.curToken, []token.TokenType{token.IDENT, token.LBRACKET, token.LBRACE}, "expected a pattern, got %s instead", p.curToken.Type)
+	return nil
+}
+
+// [a, [b, c], ...rest]
+func (p *Parser) parseArrayPattern(refutable bool) ast.Expression {
+	pattern := &ast.ArrayPattern{Token: p.curToken, Elements: []ast.Expression{}}
+
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		return pattern
+	}
+
+	for {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+
+			// Nothing can follow the rest element
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		element := p.parsePattern(refutable)
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
+// {name, age: years, address: {city}}
+func (p *Parser) parseHashPattern(refutable bool) ast.Expression {
+	pattern := &ast.HashPattern{Token: p.curToken, Keys: []*ast.Identifier{}, Values: []ast.Expression{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		key := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		var value ast.Expression = key
+		if p.peekTokenIs(token.COLON) {
+			p.nextToken()
+			p.nextToken()
+
+			if value = p.parsePattern(refutable); value == nil {
+				return nil
+			}
+		}
+
+		pattern.Keys = append(pattern.Keys, key)
+		pattern.Values = append(pattern.Values, value)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+
+	return pattern
+}
+
+// Macros only take plain identifiers as parameters
 func (p *Parser) parseFunctionParameter() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
@@ -433,21 +1122,91 @@ func (p *Parser) parseMacroLiteral() ast.Expression {
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
-	return &ast.CallExpression{Token: p.curToken, Function: function, Arguments: p.parseExpressionList(token.RPAREN)}
+	return &ast.CallExpression{Token: p.curToken, Function: function, Arguments: p.parseCallArguments()}
 }
 
+// Like any other list of expressions, but an argument can also be bound to a parameter by name: f(1, b: 2)
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+
+		return args
+	}
+
+	p.nextToken()
+	args = append(args, p.parseCallArgument())
+
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		p.nextToken()
+		args = append(args, p.parseCallArgument())
+	}
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return args
+}
+
+func (p *Parser) parseCallArgument() ast.Expression {
+	if !p.curTokenIs(token.IDENT) || !p.peekTokenIs(token.COLON) {
+		return p.parseExpression(LOWEST)
+	}
+
+	arg := &ast.KeywordArgument{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}}
+
+	p.nextToken()
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+
+	return arg
+}
+
+// ...value, valid in the arguments of a call and in array literals
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	exp := &ast.SpreadExpression{Token: p.curToken}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+
+	return exp
+}
+
+// array[index], or a slice when there's a colon: array[start:end], where both bounds are optional
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
 
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+
+		exp.Index = p.parseExpression(LOWEST)
+	}
+
+	if !p.peekTokenIs(token.COLON) {
+		if !p.expectPeek(token.RBRACKET) {
+			return nil
+		}
+
+		return exp
+	}
+
 	p.nextToken()
+	slice := &ast.SliceExpression{Token: exp.Token, Left: left, Start: exp.Index}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
 
-	exp.Index = p.parseExpression(LOWEST)
+		slice.End = p.parseExpression(LOWEST)
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
 
-	return exp
+	return slice
 }
 
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
@@ -481,6 +1240,28 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	switch {
+	case p.curTokenIs(token.LBRACE):
+		p.depth += 1
+	case p.curTokenIs(token.RBRACE) && p.depth > 0:
+		p.depth -= 1
+	}
+
+	switch p.curToken.Type {
+	case token.LPAREN, token.LBRACKET, token.LBRACE:
+		p.nesting += 1
+	case token.RPAREN, token.RBRACKET, token.RBRACE:
+		if p.nesting > 0 {
+			p.nesting -= 1
+		}
+	}
+
+	// Lexical errors are reported verbatim, in the order the lexer found them
+	for _, err := range p.l.Errors()[p.lexerErrors:] {
+		p.errors = append(p.errors, fromLexerError(err))
+	}
+	p.lexerErrors = len(p.l.Errors())
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -504,8 +1285,43 @@ func (p *Parser) peekTokenIs(t token.TokenType) bool {
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("Expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.error(UNEXPECTED_TOKEN, p.peekToken, []token.TokenType{t}, "expected next token to be %s, got %s instead", t, p.peekToken.Type)
+}
+
+// Panic-mode recovery: we skip the tokens of the broken statement and stop with curToken on its last one,
+// so the caller can go on with the next statement. A statement ends with a semicolon at its own nesting level,
+// before the brace closing the block it's in, or before a keyword that can only start a new statement.
+// In that last case we assume the braces opened by the broken statement are missing. A brace we find while
+// skipping was really written though, as in if (x { ... }, so we skip everything up to the matching one
+func (p *Parser) synchronize(level int) {
+	p.panicking = false
+	floor := p.depth // braces above this one were opened while skipping
+
+	for p.depth >= level && !p.curTokenIs(token.EOF) {
+		if p.depth < floor {
+			floor = p.depth
+		}
+
+		if p.depth == level && p.curTokenIs(token.SEMICOLON) {
+			return
+		}
+
+		switch p.peekToken.Type {
+		case token.EOF:
+			return
+		case token.LET, token.CONST, token.RETURN, token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+			if p.depth == floor {
+				p.depth = level
+				return
+			}
+		case token.RBRACE:
+			if p.depth == level && level > 0 {
+				return
+			}
+		}
+
+		p.nextToken()
+	}
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -2,10 +2,13 @@ package parser
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/akyrey/monkey-programming-language/ast"
 	"github.com/akyrey/monkey-programming-language/lexer"
+	"github.com/akyrey/monkey-programming-language/token"
 )
 
 func TestLetStatements(t *testing.T) {
@@ -43,6 +46,122 @@ func TestLetStatements(t *testing.T) {
 	}
 }
 
+func TestLetStatementPatterns(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedPattern string
+	}{
+		{"let [a, b] = x;", "[a, b]"},
+		{"let [] = x;", "[]"},
+		{"let [a, ...rest] = x;", "[a, ...rest]"},
+		{"let [...rest] = x;", "[...rest]"},
+		{"let {name, age} = x;", "{name, age}"},
+		{"let {name: n, age,} = x;", "{name: n, age}"},
+		{"let {} = x;", "{}"},
+		{"let [a, {b, c: [d, e]}] = x;", "[a, {b, c: [d, e]}]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.LetStatement. Got %T", program.Statements[0])
+		}
+
+		if stmt.Name != nil {
+			t.Errorf("stmt.Name is not nil. Got %s", stmt.Name)
+		}
+
+		if stmt.Pattern == nil || stmt.Pattern.String() != tt.expectedPattern {
+			t.Errorf("stmt.Pattern wrong. Want %q. Got %v", tt.expectedPattern, stmt.Pattern)
+			continue
+		}
+
+		testIdentifier(t, stmt.Value, "x")
+
+		if stmt.String() != "let "+tt.expectedPattern+" = x;" {
+			t.Errorf("stmt.String() wrong. Got %q", stmt.String())
+		}
+	}
+}
+
+func TestPatternErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"let [1] = x;", "1:6: expected a pattern, got INT instead"},
+		{"let [a, ...b, c] = x;", "1:13: expected next token to be ], got , instead"},
+		{`let {"a"} = x;`, "1:6: expected next token to be IDENT, got STRING instead"},
+		{"let {a b} = x;", "1:8: expected next token to be ,, got IDENT instead"},
+		{"fn([a, 2]) { a }", "1:8: expected a pattern, got INT instead"},
+		{"match (x) { -a => 1 }", "1:13: expected a pattern, got - instead"},
+		{"match (x) { 1 => 2 3 => 4 }", "1:20: expected next token to be ,, got INT instead"},
+		{"match (x) { 1, 2 }", "1:14: expected next token to be =>, got , instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 || errors[0] != tt.expectedError {
+			t.Errorf("wrong error for %q. Want %q. Got %q", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestConstStatements(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedIdentifier string
+		expectedValue      interface{}
+	}{
+		{"const x = 5;", "x", 5},
+		{"const y = true", "y", true},
+		{"const foobar = y;", "foobar", "y"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ConstStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.ConstStatement. Got %T", program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedIdentifier) {
+			return
+		}
+
+		if !testLiteralExpression(t, stmt.Value, tt.expectedValue) {
+			return
+		}
+
+		if stmt.String() != tt.input && stmt.String() != tt.input+";" {
+			t.Errorf("stmt.String() wrong. Got %q", stmt.String())
+		}
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -149,6 +268,114 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0XfF", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0xFF_FF", 65535},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. Got %T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. Got %d", tt.expected, literal.Value)
+		}
+
+		if literal.TokenLiteral() != tt.input {
+			t.Errorf("literal.TokenLiteral not %s. Got %s", tt.input, literal.TokenLiteral())
+		}
+	}
+}
+
+func TestMalformedIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedError string
+	}{
+		{"0xFG", `1:1: invalid digit 'G' in hexadecimal literal "0xFG"`},
+		{"0b102", `1:1: invalid digit '2' in binary literal "0b102"`},
+		{"0o9", `1:1: invalid digit '9' in octal literal "0o9"`},
+		{"0x", `1:1: hexadecimal literal "0x" has no digits`},
+		{"1__0", `1:1: '_' must separate successive digits in number literal "1__0"`},
+		{"1_", `1:1: '_' must separate successive digits in number literal "1_"`},
+		{"0x1FFFFFFFFFFFFFFFF", `1:1: integer literal "0x1FFFFFFFFFFFFFFFF" out of range`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 error for %s, got=%v", tt.input, errors)
+			continue
+		}
+
+		if errors[0] != tt.expectedError {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expectedError, errors[0])
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{"0.5", 0.5},
+		{"1e3", 1000},
+		{"2.5E-1", 0.25},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. Got %T", program.Statements[0])
+		}
+
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. Got %T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. Got %g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input        string
@@ -268,6 +495,34 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 		{"add(a + b + c * d / f + g)", "add((((a + b) + ((c * d) / f)) + g))"},
 		{"a * [1, 2, 3, 4][b * c] * d", "((a * ([1, 2, 3, 4][(b * c)])) * d)"},
 		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))"},
+		{"a | b ^ c & d", "(a | (b ^ (c & d)))"},
+		{"a & b == c", "(a & (b == c))"},
+		{"1 << 2 + 3", "(1 << (2 + 3))"},
+		{"a >> 1 < b << 1", "((a >> 1) < (b << 1))"},
+		{"~a & ~b", "((~a) & (~b))"},
+		{"a | b | c", "((a | b) | c)"},
+		{"a <= b == c >= d", "((a <= b) == (c >= d))"},
+		{"a + b % c", "(a + (b % c))"},
+		{"a || b && c", "(a || (b && c))"},
+		{"a && b || c && d", "((a && b) || (c && d))"},
+		{"a == b && c != d", "((a == b) && (c != d))"},
+		{"a | b && c", "((a | b) && c)"},
+		{"!a || b", "((!a) || b)"},
+		{"x = 5", "(x = 5)"},
+		{"x = y = 5", "(x = (y = 5))"},
+		{"x = 1 + 2 * 3", "(x = (1 + (2 * 3)))"},
+		{"arr[0] = a || b", "((arr[0]) = (a || b))"},
+		{"a[b][c] = d", "(((a[b])[c]) = d)"},
+		{"let a = b = 1;", "let a = (b = 1);"},
+		{"f(x = 1, 2)", "f((x = 1), 2)"},
+		{"x |> f", "f(x)"},
+		{"x |> f(1, 2)", "f(x, 1, 2)"},
+		{"x |> f(1) |> g", "g(f(x, 1))"},
+		{"1 + 2 |> f(3)", "f((1 + 2), 3)"},
+		{"a || b |> f", "f((a || b))"},
+		{"y = x |> f", "(y = f(x))"},
+		{"x |> a[0]", "(a[0])(x)"},
+		{"x |> f(y |> g)", "f(x, g(y))"},
 	}
 
 	for _, tt := range tests {
@@ -395,41 +650,614 @@ func TestIfElseExpression(t *testing.T) {
 		t.Fatalf("exp not *ast.IfExpression. Got %T", stmt.Expression)
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
-		return
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(exp.Consequence.Statements) != 1 {
+		t.Errorf("consequence is not 1 statements. Got %d", len(exp.Consequence.Statements))
+	}
+
+	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("exp.Consequence.Statements[0] is not ast.ExpressionStatement. Got %T", exp.Consequence.Statements[0])
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("exp.Alternative.Statements[0] is not ast.ExpressionStatement. Got %T", exp.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Expression, "y") {
+		return
+	}
+}
+
+func TestElseIfExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		nested         string
+		expectedString string
+	}{
+		{
+			"if (x < y) { x } else if (x > y) { y }",
+			"if (x < y) { x } else { if (x > y) { y } }",
+			"if ((x < y)) { x } else if ((x > y)) { y }",
+		},
+		{
+			"if (a) { 1 } else if (b) { 2 } else if (c) { 3 } else { 4 }",
+			"if (a) { 1 } else { if (b) { 2 } else { if (c) { 3 } else { 4 } } }",
+			"if (a) { 1 } else if (b) { 2 } else if (c) { 3 } else { 4 }",
+		},
+		{
+			"if (a) { let x = 1; x } else if (b) { if (c) { 2 } else { 3 } } else { 4 }",
+			"if (a) { let x = 1; x } else { if (b) { if (c) { 2 } else { 3 } } else { 4 } }",
+			"if (a) { let x = 1; x } else if (b) { if (c) { 2 } else { 3 } } else { 4 }",
+		},
+		{
+			"if (x) { a; b } else if (y) { c; return d; e } else { f; g }",
+			"if (x) { a; b } else { if (y) { c; return d; e } else { f; g } }",
+			"if (x) { a; b } else if (y) { c; return d; e } else { f; g }",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.IfExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression not *ast.IfExpression. Got %T", stmt.Expression)
+		}
+
+		if exp.Alternative == nil || len(exp.Alternative.Statements) != 1 {
+			t.Fatalf("exp.Alternative is not 1 statement. Got %v", exp.Alternative)
+		}
+
+		alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("exp.Alternative.Statements[0] is not ast.ExpressionStatement. Got %T", exp.Alternative.Statements[0])
+		}
+
+		if _, ok := alternative.Expression.(*ast.IfExpression); !ok {
+			t.Fatalf("alternative.Expression not *ast.IfExpression. Got %T", alternative.Expression)
+		}
+
+		if program.String() != tt.expectedString {
+			t.Errorf("program.String() wrong. Want %q. Got %q", tt.expectedString, program.String())
+		}
+
+		// The chain is the same as the nested if expressions
+		nested := New(lexer.New(tt.nested)).ParseProgram()
+		if !sameAST(nested, program) {
+			t.Errorf("program differs from the nested form. Want %q. Got %q", nested.String(), program.String())
+		}
+
+		// And the string form gives back the same program
+		p = New(lexer.New(program.String()))
+		reparsed := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if !sameAST(program, reparsed) {
+			t.Errorf("program.String() doesn't parse back to the same program. Want %q. Got %q", program.String(), reparsed.String())
+		}
+	}
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{"fn() {};", []string{}},
+		{"fn(x) {};", []string{"x"}},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. Want %d. Got %d", len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+	}
+}
+
+func TestFunctionDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expectedRest   string
+		expectedString string
+	}{
+		{"fn(a, b = 2) {};", []string{"a", "b"}, "", "a, b = 2"},
+		{"fn(a = 1 + 2, ...rest) {};", []string{"a"}, "rest", "a = (1 + 2), ...rest"},
+		{"fn(...rest) {};", []string{}, "rest", "...rest"},
+		{"fn(a, b = a * 2, c) {};", []string{"a", "b", "c"}, "", "a, b = (a * 2), c"},
+		{"fn([a, b], {c} = {}) {};", []string{"[a, b]", "{c}"}, "", "[a, b], {c} = {}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) || len(function.Defaults) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong. Want %d. Got %d with %d defaults", len(tt.expectedParams), len(function.Parameters), len(function.Defaults))
+		}
+
+		for i, ident := range tt.expectedParams {
+			// A destructured parameter has its pattern instead of a name
+			if function.Patterns[i] == nil {
+				testLiteralExpression(t, function.Parameters[i], ident)
+				continue
+			}
+
+			if function.Parameters[i] != nil {
+				t.Errorf("function.Parameters[%d] is not nil. Got %s", i, function.Parameters[i])
+			}
+
+			if function.Patterns[i].String() != ident {
+				t.Errorf("function.Patterns[%d] wrong. Want %q. Got %q", i, ident, function.Patterns[i])
+			}
+		}
+
+		if tt.expectedRest == "" {
+			if function.Rest != nil {
+				t.Errorf("function.Rest is not nil. Got %s", function.Rest)
+			}
+		} else {
+			testIdentifier(t, function.Rest, tt.expectedRest)
+		}
+
+		if function.ParametersString() != tt.expectedString {
+			t.Errorf("function.ParametersString() wrong. Want %q. Got %q", tt.expectedString, function.ParametersString())
+		}
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input      string
+		equivalent string
+	}{
+		{"x => x * 2", "fn(x) { x * 2 }"},
+		{"(x) => x * 2", "fn(x) { x * 2 }"},
+		{"() => 1", "fn() { 1 }"},
+		{"(a, b = 1, ...rest) => a + b", "fn(a, b = 1, ...rest) { a + b }"},
+		{"(...rest) => rest", "fn(...rest) { rest }"},
+		{"x => y => x + y", "fn(x) { fn(y) { x + y } }"},
+		{"f(x => x, 1)", "f(fn(x) { x }, 1)"},
+		{"let f = (a) => a |> g;", "let f = fn(a) { a |> g };"},
+		{"x = () => x", "x = fn() { x }"},
+		{"match (x) { 1 => y => y }", "match (x) { 1 => fn(y) { y } }"},
+		{"match (x) { y if (y > 1) => 1 }", "match (x) { y if y > 1 => 1 }"},
+		{"match (x) { y if (ok) => 1 }", "match (x) { y if ok => 1 }"},
+		{"match (x) { y if f((a) => a) => 1 }", "match (x) { y if f(fn(a) { a }) => 1 }"},
+		{"match (x) { y if {a: (b) => b} => 1 }", "match (x) { y if {a: fn(b) { b }} => 1 }"},
+		{"match (x) { y if ok => (a) => a }", "match (x) { y if ok => fn(a) { a } }"},
+		{"(1 + 2) * 3", "((1 + 2) * 3)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		expected := New(lexer.New(tt.equivalent)).ParseProgram()
+
+		if program.String() != expected.String() {
+			t.Errorf("wrong program for %q. Want %q. Got %q", tt.input, expected.String(), program.String())
+		}
+	}
+}
+
+func TestArrowFunctionErrors(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedKind  ErrorKind
+		expectedError string
+	}{
+		{"(1) => 1", INVALID_PARAMETER, "1:5: invalid parameter in arrow function: 1"},
+		{"(a[0] = 1) => 1", INVALID_PARAMETER, "1:12: invalid parameter in arrow function: ((a[0]) = 1)"},
+		{"(...a, b) => 1", INVALID_PARAMETER, "1:11: invalid parameter in arrow function: ...a"},
+		{"a + b => 1", INVALID_PARAMETER, "1:7: invalid parameter in arrow function: (a + b)"},
+		{"(a, b)", UNEXPECTED_TOKEN, "1:7: expected next token to be =>, got EOF instead"},
+		{"() + 1", UNEXPECTED_TOKEN, "1:4: expected next token to be =>, got + instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.ParseErrors()
+		if len(errors) == 0 {
+			t.Errorf("no errors for %q", tt.input)
+			continue
+		}
+
+		if errors[0].Kind != tt.expectedKind || errors[0].Error() != tt.expectedError {
+			t.Errorf("wrong error for %q. Want %s %q. Got %s %q", tt.input, tt.expectedKind, tt.expectedError, errors[0].Kind, errors[0].Error())
+		}
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedName   string
+		expectedParams []string
+		expectedString string
+	}{
+		{"fn add(x, y) { x + y }", "add", []string{"x", "y"}, "fn add(x, y)(x + y)"},
+		{"fn nothing() {};", "nothing", []string{}, "fn nothing()"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] not *ast.FunctionStatement. Got %T", program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedName) {
+			return
+		}
+
+		if len(stmt.Function.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("length parameters wrong. Want %d. Got %d", len(tt.expectedParams), len(stmt.Function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, stmt.Function.Parameters[i], ident)
+		}
+
+		if stmt.String() != tt.expectedString {
+			t.Errorf("stmt.String() wrong. Want %q. Got %q", tt.expectedString, stmt.String())
+		}
+	}
+}
+
+func TestFunctionLiteralStatement(t *testing.T) {
+	input := "fn(x) { x }(5);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] not *ast.ExpressionStatement. Got %T", program.Statements[0])
+	}
+
+	if _, ok := stmt.Expression.(*ast.CallExpression); !ok {
+		t.Fatalf("stmt.Expression not *ast.CallExpression. Got %T", stmt.Expression)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedArms   int
+		expectedString string
+	}{
+		{"match (x) { 1 => 2 }", 1, "matchx { 1 => 2 }"},
+		{
+			`match (x) { -1 => "neg", 1.5 => "f", "s" => true, false => 0, }`,
+			4,
+			`matchx { (-1) => neg, 1.5 => f, s => true, false => 0 }`,
+		},
+		{
+			`match (p) { [a, 0] if a > 1 => a, {kind: "circle", r} => r * r, _ => 0 }`,
+			3,
+			`matchp { [a, 0] if (a > 1) => a, {kind: circle, r} => (r * r), _ => 0 }`,
+		},
+		{"match (x) {}", 0, "matchx {  }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.MatchExpression)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.MatchExpression. Got %T", stmt.Expression)
+		}
+
+		if len(exp.Arms) != tt.expectedArms {
+			t.Errorf("wrong number of arms. Want %d. Got %d", tt.expectedArms, len(exp.Arms))
+		}
+
+		if exp.String() != tt.expectedString {
+			t.Errorf("exp.String() wrong. Want %q. Got %q", tt.expectedString, exp.String())
+		}
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y }`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. Got %T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.FunctionLiteral. Got %T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. Want 2. Got %d\n", len(function.Parameters))
+	}
+
+	testLiteralExpression(t, function.Parameters[0], "x")
+	testLiteralExpression(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statement. Got %T", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+
+	if !ok {
+		t.Fatalf("function body stmt is not an ast.ExpressionStatement. Got %T", function.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. Got %T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+
+	if !ok {
+		t.Fatalf("stmt.Expression not *ast.CallExpression. Got %T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("Wrong length of arguments. Got %d", len(exp.Arguments))
+	}
+
+	testLiteralExpression(t, exp.Arguments[0], 1)
+	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
+	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+}
+
+func TestCallExpressionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedIdent string
+		expectedArgs  []string
+	}{
+		{
+			input:         "add();",
+			expectedIdent: "add",
+			expectedArgs:  []string{},
+		},
+		{
+			input:         "add(1);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1"},
+		},
+		{
+			input:         "add(1, 2 * 3, 4 + 5);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1", "(2 * 3)", "(4 + 5)"},
+		},
+		{
+			input:         "add(1, ...rest, b: 2 * 3);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"1", "...rest", "b: (2 * 3)"},
+		},
+		{
+			input:         "add(...[1, 2], x: {1: 2}[1]);",
+			expectedIdent: "add",
+			expectedArgs:  []string{"...[1, 2]", "x: ({1:2}[1])"},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.CallExpression)
+
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.CallExpression. Got %T", stmt.Expression)
+		}
+
+		if !testIdentifier(t, exp.Function, tt.expectedIdent) {
+			return
+		}
+
+		if len(exp.Arguments) != len(tt.expectedArgs) {
+			t.Fatalf("wrong number of arguments. Want %d. Got %d", len(tt.expectedArgs), len(exp.Arguments))
+		}
+
+		for i, arg := range tt.expectedArgs {
+			if exp.Arguments[i].String() != arg {
+				t.Errorf("argument %d wrong. Want %q. Got %q", i, arg, exp.Arguments[i].String())
+			}
+		}
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello world";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.StringLiteral. Got %T", stmt.Expression)
+	}
+
+	if literal.Value != "hello world" {
+		t.Errorf("literal.Value not %q. Got %q", "hello world", literal.Value)
+	}
+}
+
+func TestRawStringLiteralExpression(t *testing.T) {
+	input := "let query = `SELECT *\nFROM users`;\nlet doc = \"\"\"\n    {\"a\": 1}\n    \"\"\";"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. Got %d", len(program.Statements))
+	}
+
+	tests := []string{"SELECT *\nFROM users", `{"a": 1}`}
+
+	for i, expected := range tests {
+		stmt := program.Statements[i].(*ast.LetStatement)
+		literal, ok := stmt.Value.(*ast.StringLiteral)
+
+		if !ok {
+			t.Fatalf("stmt.Value is not ast.StringLiteral. Got %T", stmt.Value)
+		}
+
+		if literal.Value != expected {
+			t.Errorf("literal.Value not %q. Got %q", expected, literal.Value)
+		}
 	}
+}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("consequence is not 1 statements. Got %d", len(exp.Consequence.Statements))
-	}
+func TestUnexpectedCharactersAreReportedOnce(t *testing.T) {
+	input := `let x = 5 @ 3;
+let y = x # 2;`
 
-	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("exp.Consequence.Statements[0] is not ast.ExpressionStatement. Got %T", exp.Consequence.Statements[0])
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	expected := []string{
+		"1:11: unexpected character '@'",
+		"2:11: unexpected character '#'",
 	}
 
-	if !testIdentifier(t, consequence.Expression, "x") {
-		return
+	errors := p.Errors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. Want %d. Got %d (%q)", len(expected), len(errors), errors)
 	}
 
-	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("exp.Alternative.Statements[0] is not ast.ExpressionStatement. Got %T", exp.Alternative.Statements[0])
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("errors[%d] wrong. Want %q. Got %q", i, msg, errors[i])
+		}
 	}
 
-	if !testIdentifier(t, alternative.Expression, "y") {
-		return
+	// Parsing goes on as if the characters weren't there
+	if len(program.Statements) != 4 {
+		t.Errorf("program.Statements does not contain 4 statements. Got %d", len(program.Statements))
 	}
 }
 
-func TestFunctionParameterParsing(t *testing.T) {
+func TestTemplateLiteralParsing(t *testing.T) {
 	tests := []struct {
-		input          string
-		expectedParams []string
+		input         string
+		expectedParts int
+		expected      string
 	}{
-		{"fn() {};", []string{}},
-		{"fn(x) {};", []string{"x"}},
-		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+		{`"Hello ${name}!"`, 3, "Hello ${name}!"},
+		{`"${a + b}"`, 1, "${(a + b)}"},
+		{`"${a} and ${b[0]}"`, 3, "${a} and ${(b[0])}"},
+		{`"sum: ${add(1, 2 * 3)}"`, 2, "sum: ${add(1, (2 * 3))}"},
+		{`"nested ${"inner ${x}"}"`, 2, "nested ${inner ${x}}"},
 	}
 
 	for _, tt := range tests {
@@ -440,121 +1268,245 @@ func TestFunctionParameterParsing(t *testing.T) {
 		checkParserErrors(t, p)
 
 		stmt := program.Statements[0].(*ast.ExpressionStatement)
-		function := stmt.Expression.(*ast.FunctionLiteral)
+		template, ok := stmt.Expression.(*ast.TemplateLiteral)
 
-		if len(function.Parameters) != len(tt.expectedParams) {
-			t.Errorf("length parameters wrong. Want %d. Got %d", len(tt.expectedParams), len(function.Parameters))
+		if !ok {
+			t.Fatalf("exp not *ast.TemplateLiteral. Got %T", stmt.Expression)
 		}
 
-		for i, ident := range tt.expectedParams {
-			testLiteralExpression(t, function.Parameters[i], ident)
+		if len(template.Parts) != tt.expectedParts {
+			t.Errorf("template has wrong number of parts. Want %d. Got %d", tt.expectedParts, len(template.Parts))
+		}
+
+		if template.String() != tt.expected {
+			t.Errorf("template.String() wrong. Want %q. Got %q", tt.expected, template.String())
 		}
 	}
 }
 
-func TestFunctionLiteralParsing(t *testing.T) {
-	input := `fn(x, y) { x + y }`
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < 10) { x; break; }`
 
 	l := lexer.New(input)
 	p := New(l)
-
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
 
 	if len(program.Statements) != 1 {
-		t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		t.Fatalf("program.Statements does not contain 1 statement. Got %d", len(program.Statements))
 	}
 
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
 	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. Got %T", program.Statements[0])
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. Got %T", program.Statements[0])
 	}
 
-	function, ok := stmt.Expression.(*ast.FunctionLiteral)
-
-	if !ok {
-		t.Fatalf("stmt.Expression not *ast.FunctionLiteral. Got %T", stmt.Expression)
+	if !testInfixExpression(t, stmt.Condition, "x", "<", 10) {
+		return
 	}
 
-	if len(function.Parameters) != 2 {
-		t.Fatalf("function literal parameters wrong. Want 2. Got %d\n", len(function.Parameters))
+	if len(stmt.Body.Statements) != 2 {
+		t.Fatalf("body does not contain 2 statements. Got %d", len(stmt.Body.Statements))
 	}
 
-	testLiteralExpression(t, function.Parameters[0], "x")
-	testLiteralExpression(t, function.Parameters[1], "y")
-
-	if len(function.Body.Statements) != 1 {
-		t.Fatalf("function.Body.Statements has not 1 statement. Got %T", len(function.Body.Statements))
+	if _, ok := stmt.Body.Statements[1].(*ast.BreakStatement); !ok {
+		t.Errorf("body.Statements[1] is not ast.BreakStatement. Got %T", stmt.Body.Statements[1])
 	}
 
-	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
-
-	if !ok {
-		t.Fatalf("function body stmt is not an ast.ExpressionStatement. Got %T", function.Body.Statements[0])
+	if stmt.String() != "while ((x < 10)) xbreak;" {
+		t.Errorf("stmt.String() wrong. Got %q", stmt.String())
 	}
-
-	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
 }
 
-func TestCallExpressionParsing(t *testing.T) {
-	input := "add(1, 2 * 3, 4 + 5);"
+func TestForStatement(t *testing.T) {
+	input := `for (item in [1, 2]) { if (item == 1) { continue } item };`
 
 	l := lexer.New(input)
 	p := New(l)
-
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
 
 	if len(program.Statements) != 1 {
-		t.Fatalf("program.Statements does not contain 1 statements. Got %d", len(program.Statements))
+		t.Fatalf("program.Statements does not contain 1 statement. Got %d", len(program.Statements))
 	}
 
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
-
+	stmt, ok := program.Statements[0].(*ast.ForStatement)
 	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. Got %T", program.Statements[0])
+		t.Fatalf("program.Statements[0] is not ast.ForStatement. Got %T", program.Statements[0])
 	}
 
-	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !testIdentifier(t, stmt.Variable, "item") {
+		return
+	}
 
-	if !ok {
-		t.Fatalf("stmt.Expression not *ast.CallExpression. Got %T", stmt.Expression)
+	if stmt.Iterable.String() != "[1, 2]" {
+		t.Errorf("stmt.Iterable wrong. Got %q", stmt.Iterable.String())
 	}
 
-	if !testIdentifier(t, exp.Function, "add") {
-		return
+	if stmt.String() != "for (item in [1, 2]) if ((item == 1)) { continue; }item" {
+		t.Errorf("stmt.String() wrong. Got %q", stmt.String())
 	}
+}
 
-	if len(exp.Arguments) != 3 {
-		t.Fatalf("Wrong length of arguments. Got %d", len(exp.Arguments))
+func TestLoopErrorRecovery(t *testing.T) {
+	input := `for (1 in x) { x }
+while x { x }
+let y = 1;`
+
+	expected := []string{
+		"1:6: expected next token to be IDENT, got INT instead",
+		"2:7: expected next token to be (, got IDENT instead",
 	}
 
-	testLiteralExpression(t, exp.Arguments[0], 1)
-	testInfixExpression(t, exp.Arguments[1], 2, "*", 3)
-	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	if strings.Join(p.Errors(), "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong errors.\nWant %q\nGot  %q", expected, p.Errors())
+	}
+
+	last := program.Statements[len(program.Statements)-1]
+	if last.String() != "let y = 1;" {
+		t.Errorf("last statement wrong. Got %q", last.String())
+	}
 }
 
-func TestCallExpressionParameterParsing(t *testing.T) {
+func TestInvalidAssignmentTargets(t *testing.T) {
 	tests := []struct {
 		input         string
-		expectedIdent string
-		expectedArgs  []string
+		expectedError string
+	}{
+		{"1 = 2", "1:3: cannot assign to 1"},
+		{"f() = 2", "1:5: cannot assign to f()"},
+		{"a + b = 3", "1:7: cannot assign to (a + b)"},
+		{"x = 1 = 2", "1:7: cannot assign to 1"},
+		{"a[1:2] = 3", "1:8: cannot assign to (a[1:2])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.ParseErrors()
+		if len(errors) != 1 {
+			t.Errorf("expected 1 error for %s, got=%q", tt.input, p.Errors())
+			continue
+		}
+
+		if errors[0].Kind != INVALID_ASSIGNMENT || errors[0].Error() != tt.expectedError {
+			t.Errorf("wrong error. expected=%q, got=%s %q", tt.expectedError, errors[0].Kind, errors[0].Error())
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements []string
 	}{
 		{
-			input:         "add();",
-			expectedIdent: "add",
-			expectedArgs:  []string{},
+			"let x 5;\nlet y = 10;\nlet = 3;\nlet z = y;",
+			[]string{
+				"1:7: expected next token to be =, got INT instead",
+				"3:5: expected next token to be IDENT, got = instead",
+			},
+			[]string{"let y = 10;", "let z = y;"},
 		},
 		{
-			input:         "add(1);",
-			expectedIdent: "add",
-			expectedArgs:  []string{"1"},
+			"let f = fn(...rest, a) { rest };\nlet b = 3;",
+			[]string{"1:19: expected next token to be ), got , instead"},
+			[]string{"let f = ;", "let b = 3;"},
 		},
 		{
-			input:         "add(1, 2 * 3, 4 + 5);",
-			expectedIdent: "add",
-			expectedArgs:  []string{"1", "(2 * 3)", "(4 + 5)"},
+			"let a = (1 + 2;\nlet b = 3;",
+			[]string{"1:15: expected next token to be ), got ; instead"},
+			[]string{"let a = ;", "let b = 3;"},
+		},
+		{
+			"let f = fn() { let x = ; x };\nlet y = ;",
+			[]string{
+				"1:24: no prefix parse function for ; found",
+				"2:9: no prefix parse function for ; found",
+			},
+			[]string{"let f = fn()let x = ;x;", "let y = ;"},
+		},
+		{
+			// The braces opened while skipping the broken statement are matched
+			"let h = {1 2, 3: {4: 5; 6: 7}; 8: 9};\nlet z = ;",
+			[]string{
+				"1:12: expected next token to be :, got INT instead",
+				"2:9: no prefix parse function for ; found",
+			},
+			[]string{"let h = ;", "let z = ;"},
+		},
+		{
+			// A missing brace doesn't hide the statements after it
+			"let h = {\"a\": 1;\nlet y = 2;\nlet z = ;",
+			[]string{
+				"1:16: expected next token to be ,, got ; instead",
+				"3:9: no prefix parse function for ; found",
+			},
+			[]string{"let h = ;", "let y = 2;", "let z = ;"},
+		},
+		{
+			"}\nlet x = 1;",
+			[]string{"1:1: no prefix parse function for } found"},
+			[]string{"", "let x = 1;"},
+		},
+		{
+			// The broken statement ends on the brace closing the block
+			"if (x) { let a = }\nlet y = 2;",
+			[]string{"1:18: no prefix parse function for } found"},
+			[]string{"if (x) { let a = ; }", "let y = 2;"},
+		},
+		{
+			// The blocks after a missing ) were really written, so they are skipped up to their closing brace
+			"if (x { let a = 1; a } else { let b = 2; b }; let c = 3;",
+			[]string{"1:7: expected next token to be ), got { instead"},
+			[]string{"", "let c = 3;"},
+		},
+		{
+			"let f = fn(a { let b = a; b }; let c = 1;",
+			[]string{"1:14: expected next token to be ), got { instead"},
+			[]string{"let f = ;", "let c = 1;"},
+		},
+		{
+			"while (x { let a = 1; }",
+			[]string{"1:10: expected next token to be ), got { instead"},
+			[]string{},
+		},
+		{
+			"let g = fn() { if (x { let a = 1; a }; let b = 2; b };\nlet c = ;",
+			[]string{
+				"1:22: expected next token to be ), got { instead",
+				"2:9: no prefix parse function for ; found",
+			},
+			[]string{"let g = fn()let b = 2;b;", "let c = ;"},
+		},
+		{
+			"let s = \"${a b}\";\nlet t = 1;",
+			[]string{"1:14: expected } after interpolated expression, got IDENT instead"},
+			[]string{"let s = ;", "let t = 1;"},
+		},
+		{
+			"let s = \"x ${} y ${a}\";\nlet t = 1;",
+			[]string{"1:14: empty interpolation in string"},
+			[]string{"let s = ;", "let t = 1;"},
+		},
+		{
+			// 010 isn't read as an octal number
+			"let a = 010;\nlet b = 1;",
+			[]string{`1:9: leading zeros are not allowed in decimal literal "010", use 0o for octal`},
+			[]string{"let a = ;", "let b = 1;"},
+		},
+		{
+			// A broken interpolated expression leaves no template with a missing part
+			"let s = \"a ${ 0xZZ } b\";\nlet t = 1;",
+			[]string{"1:15: invalid digit 'Z' in hexadecimal literal \"0xZZ\""},
+			[]string{"let s = ;", "let t = 1;"},
 		},
 	}
 
@@ -562,48 +1514,147 @@ func TestCallExpressionParameterParsing(t *testing.T) {
 		l := lexer.New(tt.input)
 		p := New(l)
 		program := p.ParseProgram()
-		checkParserErrors(t, p)
 
-		stmt := program.Statements[0].(*ast.ExpressionStatement)
-		exp, ok := stmt.Expression.(*ast.CallExpression)
+		errors := p.Errors()
+		if strings.Join(errors, "\n") != strings.Join(tt.expectedErrors, "\n") {
+			t.Errorf("wrong errors for %q.\nWant %q\nGot  %q", tt.input, tt.expectedErrors, errors)
+		}
 
-		if !ok {
-			t.Fatalf("stmt.Expression is not ast.CallExpression. Got %T", stmt.Expression)
+		statements := []string{}
+		for _, stmt := range program.Statements {
+			statements = append(statements, stmt.String())
 		}
 
-		if !testIdentifier(t, exp.Function, tt.expectedIdent) {
-			return
+		if strings.Join(statements, "\n") != strings.Join(tt.expectedStatements, "\n") {
+			t.Errorf("wrong statements for %q.\nWant %q\nGot  %q", tt.input, tt.expectedStatements, statements)
 		}
+	}
+}
 
-		if len(exp.Arguments) != len(tt.expectedArgs) {
-			t.Fatalf("wrong number of arguments. Want %d. Got %d", len(tt.expectedArgs), len(exp.Arguments))
+func TestParseErrors(t *testing.T) {
+	input := `let x 5;
+let y = ;
+let z = 99999999999999999999;
+let s = "\q";`
+
+	expected := []ParseError{
+		{
+			Kind:     UNEXPECTED_TOKEN,
+			Message:  "expected next token to be =, got INT instead",
+			Pos:      token.Position{Offset: 6, Line: 1, Column: 7},
+			End:      token.Position{Offset: 7, Line: 1, Column: 8},
+			Expected: []token.TokenType{token.ASSIGN},
+			Found:    token.INT,
+		},
+		{
+			Kind:    MISSING_EXPRESSION,
+			Message: "no prefix parse function for ; found",
+			Pos:     token.Position{Offset: 17, Line: 2, Column: 9},
+			End:     token.Position{Offset: 18, Line: 2, Column: 10},
+			Found:   token.SEMICOLON,
+		},
+		{
+			Kind:    NUMBER_OUT_OF_RANGE,
+			Message: `integer literal "99999999999999999999" out of range`,
+			Pos:     token.Position{Offset: 27, Line: 3, Column: 9},
+			End:     token.Position{Offset: 47, Line: 3, Column: 29},
+			Found:   token.INT,
+		},
+		{
+			Kind:    lexer.BAD_ESCAPE,
+			Message: `unknown escape sequence: \q`,
+			Pos:     token.Position{Offset: 58, Line: 4, Column: 10},
+			End:     token.Position{Offset: 60, Line: 4, Column: 12},
+		},
+	}
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.ParseErrors()
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors. Want %d. Got %d (%q)", len(expected), len(errors), p.Errors())
+	}
+
+	for i, err := range errors {
+		if !reflect.DeepEqual(*err, expected[i]) {
+			t.Errorf("errors[%d] wrong.\nWant %#v\nGot  %#v", i, expected[i], *err)
 		}
 
-		for i, arg := range tt.expectedArgs {
-			if exp.Arguments[i].String() != arg {
-				t.Errorf("argument %d wrong. Want %q. Got %q", i, arg, exp.Arguments[i].String())
-			}
+		if p.Errors()[i] != err.Error() {
+			t.Errorf("string form of errors[%d] wrong. Want %q. Got %q", i, err.Error(), p.Errors()[i])
 		}
 	}
 }
 
-func TestStringLiteralExpression(t *testing.T) {
-	input := `"hello world";`
+func TestParse(t *testing.T) {
+	program, err := Parse("let x = 5; x + 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
 
-	l := lexer.New(input)
-	p := New(l)
-	program := p.ParseProgram()
-	checkParserErrors(t, p)
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. Got %d", len(program.Statements))
+	}
 
-	stmt := program.Statements[0].(*ast.ExpressionStatement)
-	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	program, err = Parse("let = 5; let y = 1; let z 2;")
+	if err == nil {
+		t.Fatalf("expected an error, got none")
+	}
 
+	if err.Error() != "1:5: expected next token to be IDENT, got = instead (and 1 more errors)" {
+		t.Errorf("wrong error. Got %q", err.Error())
+	}
+
+	list, ok := err.(ErrorList)
 	if !ok {
-		t.Fatalf("stmt.Expression is not ast.StringLiteral. Got %T", stmt.Expression)
+		t.Fatalf("err is not ErrorList. Got %T", err)
 	}
 
-	if literal.Value != "hello world" {
-		t.Errorf("literal.Value not %q. Got %q", "hello world", literal.Value)
+	if len(list) != 2 || list[1].Kind != UNEXPECTED_TOKEN || list[1].Pos.String() != "1:27" {
+		t.Errorf("wrong errors. Got %q", list.Strings())
+	}
+
+	// The statements that could be parsed are still there
+	if len(program.Statements) != 1 || program.Statements[0].String() != "let y = 1;" {
+		t.Errorf("wrong statements. Got %q", program.String())
+	}
+
+	// And the ones that couldn't are left out, instead of being nil pointers
+	program, _ = Parse("const = 1; let b = 2; while (x) 1; for x; fn f( { }; let = 3; return 4;")
+	statements := []string{}
+	for _, stmt := range program.Statements {
+		if reflect.ValueOf(stmt).IsNil() {
+			t.Fatalf("program contains a nil %T", stmt)
+		}
+		statements = append(statements, stmt.String())
+	}
+
+	if strings.Join(statements, " ") != "let b = 2; return 4;" {
+		t.Errorf("wrong statements. Got %q", statements)
+	}
+
+	program = NewDocument("const = 1; let b = 2;").Program()
+	if len(program.Statements) != 1 || program.Statements[0].String() != "let b = 2;" {
+		t.Errorf("wrong document statements. Got %q", program.String())
+	}
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	input := `let greeting = "hello;`
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	if errors[0] != "1:16: unterminated string literal" {
+		t.Errorf("wrong first error. Got %q", errors[0])
 	}
 }
 
@@ -675,6 +1726,55 @@ func TestParsingIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestParsingSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedStart interface{}
+		expectedEnd   interface{}
+		expected      string
+	}{
+		{"myArray[1:3]", 1, 3, "(myArray[1:3])"},
+		{"myArray[:n]", nil, "n", "(myArray[:n])"},
+		{"myArray[2:]", 2, nil, "(myArray[2:])"},
+		{"myArray[:]", nil, nil, "(myArray[:])"},
+		{"myArray[-2:-1]", nil, nil, "(myArray[(-2):(-1)])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		slice, ok := stmt.Expression.(*ast.SliceExpression)
+		if !ok {
+			t.Fatalf("exp not ast.SliceExpression. Got %T", stmt.Expression)
+		}
+
+		if !testIdentifier(t, slice.Left, "myArray") {
+			return
+		}
+
+		if slice.String() != tt.expected {
+			t.Errorf("slice.String() wrong. Want %q. Got %q", tt.expected, slice.String())
+		}
+
+		if tt.expectedStart != nil {
+			testLiteralExpression(t, slice.Start, tt.expectedStart)
+		}
+
+		if tt.expectedEnd != nil {
+			testLiteralExpression(t, slice.End, tt.expectedEnd)
+		}
+	}
+
+	program := New(lexer.New("a[1:][0]")).ParseProgram()
+	if program.String() != "((a[1:])[0])" {
+		t.Errorf("program.String() wrong. Got %q", program.String())
+	}
+}
+
 func TestParsingHashLiteralsStringKeys(t *testing.T) {
 	input := `{"one": 1, "two": 2, "three": 3}`
 
@@ -1026,6 +2126,47 @@ func testInfixExpression(t *testing.T, exp ast.Expression, left interface{}, ope
 	return true
 }
 
+// Compares two ASTs ignoring their tokens, so nodes parsed from different positions can be equal
+func sameAST(a, b ast.Node) bool {
+	return sameValue(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func sameValue(a, b reflect.Value) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return sameValue(a.Elem(), b.Elem())
+	case reflect.Struct:
+		if a.Type() == reflect.TypeOf(token.Token{}) {
+			return true
+		}
+		for i := 0; i < a.NumField(); i++ {
+			if !sameValue(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameValue(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
+
 func checkParserErrors(t *testing.T, p *Parser) {
 	errors := p.Errors()
 
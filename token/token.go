@@ -1,10 +1,43 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
+// Position identifies a location in the source code.
+// Line and Column start from 1, while Offset is the 0-based byte offset in the input
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // position of the first character of the token
+	End     Position // position right after the last character of the token
+	Trivia  []Trivia // comments found between the previous token and this one
+}
+
+type TriviaType string
+
+const (
+	LINE_COMMENT  = "LINE_COMMENT"  // // comment
+	BLOCK_COMMENT = "BLOCK_COMMENT" // /* comment */
+)
+
+// Trivia is source text that doesn't change the meaning of the program, like comments.
+// The lexer attaches it to the following token, so formatters and doc tools can reproduce it
+type Trivia struct {
+	Type    TriviaType
+	Literal string // the whole comment, delimiters included
+	Pos     Position
+	End     Position
 }
 
 const (
@@ -14,8 +47,14 @@ const (
 	// Identifiers + literals
 	IDENT  = "IDENT" // add, foobar, x, y, ...
 	INT    = "INT"   //1343456
+	FLOAT  = "FLOAT" // 3.14, 1e10
 	STRING = "STRING"
 
+	// Parts of strings with ${} interpolations: "HEAD ${a} MIDDLE ${b} TAIL"
+	TEMPLATE_HEAD   = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   = "TEMPLATE_TAIL"
+
 	// Operators
 	ASSIGN   = "="
 	PLUS     = "+"
@@ -23,9 +62,26 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
+
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
+
+	// Logical operators
+	AND = "&&"
+	OR  = "||"
+
+	PIPELINE = "|>"
 
-	LT = "<"
-	GT = ">"
+	// Bitwise operators
+	AMPERSAND   = "&"
+	PIPE        = "|"
+	CARET       = "^"
+	TILDE       = "~"
+	SHIFT_LEFT  = "<<"
+	SHIFT_RIGHT = ">>"
 
 	EQ     = "=="
 	NOT_EQ = "!="
@@ -34,6 +90,8 @@ const (
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	ELLIPSIS  = "..."
+	ARROW     = "=>"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -45,23 +103,37 @@ const (
 	// Keyworks
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
 	MACRO    = "MACRO"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MATCH    = "MATCH"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
-	"macro":  MACRO,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"const":    CONST,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"macro":    MACRO,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"match":    MATCH,
 }
 
 func LookupIdent(ident string) TokenType {